@@ -31,6 +31,36 @@ func (b *Bot) SendGroupMessage(group int64, message MessageChain) (int64, error)
 	return result.Get("message_id").Int(), nil
 }
 
+// SendPrivateRawMessage 发送CQ码格式的私聊消息，autoEscape为true时消息内容作为纯文本发送，不解析CQ码，返回消息id
+//
+// 可以用 MessageChain.CQString 生成CQ码格式的消息
+func (b *Bot) SendPrivateRawMessage(userId int64, message string, autoEscape bool) (int64, error) {
+	result, err := b.request("send_private_msg", &struct {
+		UserId     int64  `json:"user_id"`
+		Message    string `json:"message"`
+		AutoEscape bool   `json:"auto_escape"`
+	}{userId, message, autoEscape})
+	if err != nil {
+		return 0, err
+	}
+	return result.Get("message_id").Int(), nil
+}
+
+// SendGroupRawMessage 发送CQ码格式的群消息，autoEscape为true时消息内容作为纯文本发送，不解析CQ码，返回消息id
+//
+// 可以用 MessageChain.CQString 生成CQ码格式的消息
+func (b *Bot) SendGroupRawMessage(group int64, message string, autoEscape bool) (int64, error) {
+	result, err := b.request("send_group_msg", &struct {
+		GroupId    int64  `json:"group_id"`
+		Message    string `json:"message"`
+		AutoEscape bool   `json:"auto_escape"`
+	}{group, message, autoEscape})
+	if err != nil {
+		return 0, err
+	}
+	return result.Get("message_id").Int(), nil
+}
+
 type MessageType string
 
 const (
@@ -199,7 +229,7 @@ func (b *Bot) SetGroupCard(groupId, userId int64, card string) error {
 
 // SetGroupName 设置群名
 func (b *Bot) SetGroupName(groupId int64, groupName string) error {
-	_, err := b.request("set_group_leave", &struct {
+	_, err := b.request("set_group_name", &struct {
 		GroupId   int64  `json:"group_id"`
 		GroupName string `json:"group_name"`
 	}{groupId, groupName})
@@ -220,7 +250,7 @@ func (b *Bot) SetGroupSpecialTitle(groupId, userId int64, specialTitle string, d
 	_, err := b.request("set_group_special_title", &struct {
 		GroupId      int64  `json:"group_id"`
 		UserId       int64  `json:"user_id"`
-		SpecialTitle string `json:"specialTitle,omitempty"`
+		SpecialTitle string `json:"special_title,omitempty"`
 		Duration     int32  `json:"duration"`
 	}{groupId, userId, specialTitle, duration})
 	return err
@@ -240,7 +270,7 @@ func (b *Bot) SetFriendAddRequest(flag string, approve bool, remark string) erro
 func (b *Bot) SetGroupAddRequest(flag string, subType GroupRequestSubType, approve bool, reason string) error {
 	_, err := b.request("set_group_add_request", &struct {
 		Flag    string              `json:"flag"`
-		SubType GroupRequestSubType `json:"subType"`
+		SubType GroupRequestSubType `json:"sub_type"`
 		Approve bool                `json:"approve"`
 		Reason  string              `json:"reason,omitempty"`
 	}{flag, subType, approve, reason})
@@ -376,6 +406,56 @@ func (b *Bot) GetGroupMemberList(group int64) ([]*GroupMemberInfo, error) {
 	return groupInfo, err
 }
 
+type HonorType string
+
+const (
+	HonorTypeTalkative    HonorType = "talkative"     // 龙王
+	HonorTypePerformer    HonorType = "performer"     // 群聊之火
+	HonorTypeLegend       HonorType = "legend"        // 群聊炽焰
+	HonorTypeStrongNewbie HonorType = "strong_newbie" // 冒尖小春笋
+	HonorTypeEmotion      HonorType = "emotion"       // 快乐之源
+	HonorTypeAll          HonorType = "all"           // 所有类型
+)
+
+type CurrentTalkative struct {
+	UserId   int64  `json:"user_id"`   // QQ 号
+	Nickname string `json:"nickname"`  // 昵称
+	Avatar   string `json:"avatar"`    // 头像 URL
+	DayCount int32  `json:"day_count"` // 持续天数
+}
+
+type HonorMember struct {
+	UserId      int64  `json:"user_id"`     // QQ 号
+	Nickname    string `json:"nickname"`    // 昵称
+	Avatar      string `json:"avatar"`      // 头像 URL
+	Description string `json:"description"` // 荣誉描述
+}
+
+// GroupHonorInfo 群荣誉信息，只有获取时指定的类型对应的字段有值
+type GroupHonorInfo struct {
+	GroupId          int64             `json:"group_id"`           // 群号
+	CurrentTalkative *CurrentTalkative `json:"current_talkative"`  // 当前龙王，仅 talkative 类型时有数据
+	TalkativeList    []*HonorMember    `json:"talkative_list"`     // 历史龙王，仅 talkative 类型时有数据
+	PerformerList    []*HonorMember    `json:"performer_list"`     // 群聊之火，仅 performer 类型时有数据
+	LegendList       []*HonorMember    `json:"legend_list"`        // 群聊炽焰，仅 legend 类型时有数据
+	StrongNewbieList []*HonorMember    `json:"strong_newbie_list"` // 冒尖小春笋，仅 strong_newbie 类型时有数据
+	EmotionList      []*HonorMember    `json:"emotion_list"`       // 快乐之源，仅 emotion 类型时有数据
+}
+
+// GetGroupHonorInfo 获取群荣誉信息，groupId-群号，honorType-要获取的群荣誉类型，可传入 HonorTypeAll 获取所有数据
+func (b *Bot) GetGroupHonorInfo(groupId int64, honorType HonorType) (*GroupHonorInfo, error) {
+	result, err := b.request("get_group_honor_info", &struct {
+		GroupId int64     `json:"group_id"`
+		Type    HonorType `json:"type"`
+	}{groupId, honorType})
+	if err != nil {
+		return nil, err
+	}
+	var ret *GroupHonorInfo
+	err = json.Unmarshal([]byte(result.Raw), &ret)
+	return ret, err
+}
+
 // GetCookies 获取Cookies，domain-需要获取cookies的域名
 func (b *Bot) GetCookies(domain string) (string, error) {
 	result, err := b.request("get_cookies", &struct {
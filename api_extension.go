@@ -0,0 +1,222 @@
+package onebot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/tidwall/gjson"
+)
+
+// 本文件是 go-cqhttp 、 NapCat 、 LLOneBot 等实现广泛支持的扩展API，不属于 OneBot 11 标准。
+// 只能在 OneBot 11 下使用，OneBot实现不支持时返回的错误满足 errors.Is(err, ErrUnsupportedAction) ，
+// 并且之后再调用同一个API时直接返回错误，不再发送请求。
+
+// requestExtension 调用扩展API，已知不支持的API直接返回错误
+func (b *Bot) requestExtension(action string, params any) (gjson.Result, error) {
+	if b.version == ProtocolV12 {
+		return gjson.Result{}, fmt.Errorf("%w: %s is not available in OneBot 12", ErrUnsupportedAction, action)
+	}
+	if _, ok := b.unsupportedActions.Load(action); ok {
+		return gjson.Result{}, fmt.Errorf("%w: %s", ErrUnsupportedAction, action)
+	}
+	result, err := b.request(action, params)
+	if errors.Is(err, ErrUnsupportedAction) {
+		b.unsupportedActions.Store(action, struct{}{})
+	}
+	return result, err
+}
+
+// IsSupported 返回OneBot实现是否支持某个API，只有调用过并且返回了不支持的错误时才返回false
+func (b *Bot) IsSupported(action string) bool {
+	if b.version == ProtocolV12 {
+		return true
+	}
+	_, ok := b.unsupportedActions.Load(action)
+	return !ok
+}
+
+// SendGroupForwardMessage 发送合并转发（群聊），返回消息id和转发id
+func (b *Bot) SendGroupForwardMessage(groupId int64, nodes []*Node) (int64, string, error) {
+	result, err := b.requestExtension("send_group_forward_msg", &struct {
+		GroupId  int64        `json:"group_id"`
+		Messages MessageChain `json:"messages"`
+	}{groupId, nodesToMessageChain(nodes)})
+	if err != nil {
+		return 0, "", err
+	}
+	return result.Get("message_id").Int(), result.Get("forward_id").String(), nil
+}
+
+// SendPrivateForwardMessage 发送合并转发（好友），返回消息id和转发id
+func (b *Bot) SendPrivateForwardMessage(userId int64, nodes []*Node) (int64, string, error) {
+	result, err := b.requestExtension("send_private_forward_msg", &struct {
+		UserId   int64        `json:"user_id"`
+		Messages MessageChain `json:"messages"`
+	}{userId, nodesToMessageChain(nodes)})
+	if err != nil {
+		return 0, "", err
+	}
+	return result.Get("message_id").Int(), result.Get("forward_id").String(), nil
+}
+
+func nodesToMessageChain(nodes []*Node) MessageChain {
+	chain := make(MessageChain, 0, len(nodes))
+	for _, node := range nodes {
+		chain = append(chain, node)
+	}
+	return chain
+}
+
+// GetGroupMessageHistory 获取群消息历史记录，messageSeq-起始消息序号，为0表示从最新的消息开始
+func (b *Bot) GetGroupMessageHistory(groupId int64, messageSeq int64) ([]*GroupMessage, error) {
+	result, err := b.requestExtension("get_group_msg_history", &struct {
+		GroupId    int64 `json:"group_id"`
+		MessageSeq int64 `json:"message_seq,omitempty"`
+	}{groupId, messageSeq})
+	if err != nil {
+		return nil, err
+	}
+	var ret []*GroupMessage
+	err = json.Unmarshal([]byte(result.Get("messages").Raw), &ret)
+	return ret, err
+}
+
+// SetEssenceMessage 设置精华消息
+func (b *Bot) SetEssenceMessage(messageId int64) error {
+	_, err := b.requestExtension("set_essence_msg", &struct {
+		MessageId int64 `json:"message_id"`
+	}{messageId})
+	return err
+}
+
+// DeleteEssenceMessage 移出精华消息
+func (b *Bot) DeleteEssenceMessage(messageId int64) error {
+	_, err := b.requestExtension("delete_essence_msg", &struct {
+		MessageId int64 `json:"message_id"`
+	}{messageId})
+	return err
+}
+
+// EssenceMessage 精华消息
+type EssenceMessage struct {
+	SenderId     int64  `json:"sender_id"`     // 发送者QQ号
+	SenderNick   string `json:"sender_nick"`   // 发送者昵称
+	SenderTime   int64  `json:"sender_time"`   // 消息发送时间
+	OperatorId   int64  `json:"operator_id"`   // 操作者QQ号
+	OperatorNick string `json:"operator_nick"` // 操作者昵称
+	OperatorTime int64  `json:"operator_time"` // 精华设置时间
+	MessageId    int64  `json:"message_id"`    // 消息ID
+}
+
+// GetEssenceMessageList 获取精华消息列表
+func (b *Bot) GetEssenceMessageList(groupId int64) ([]*EssenceMessage, error) {
+	result, err := b.requestExtension("get_essence_msg_list", &struct {
+		GroupId int64 `json:"group_id"`
+	}{groupId})
+	if err != nil {
+		return nil, err
+	}
+	var ret []*EssenceMessage
+	err = json.Unmarshal([]byte(result.Raw), &ret)
+	return ret, err
+}
+
+// SendGroupNotice 发送群公告，image-图片，支持的格式同 Image 的 File 字段，不需要时传空字符串
+func (b *Bot) SendGroupNotice(groupId int64, content, image string) error {
+	_, err := b.requestExtension("_send_group_notice", &struct {
+		GroupId int64  `json:"group_id"`
+		Content string `json:"content"`
+		Image   string `json:"image,omitempty"`
+	}{groupId, content, image})
+	return err
+}
+
+// GroupNoticeImage 群公告中的图片
+type GroupNoticeImage struct {
+	Height string `json:"height"` // 图片高度
+	Width  string `json:"width"`  // 图片宽度
+	Id     string `json:"id"`     // 图片ID
+}
+
+// GroupNotice 群公告
+type GroupNotice struct {
+	SenderId    int64 `json:"sender_id"`    // 公告发表者
+	PublishTime int64 `json:"publish_time"` // 公告发表时间
+	Message     struct {
+		Text   string              `json:"text"`   // 公告内容
+		Images []*GroupNoticeImage `json:"images"` // 公告图片
+	} `json:"message"` // 公告内容
+}
+
+// GetGroupNotice 获取群公告
+func (b *Bot) GetGroupNotice(groupId int64) ([]*GroupNotice, error) {
+	result, err := b.requestExtension("_get_group_notice", &struct {
+		GroupId int64 `json:"group_id"`
+	}{groupId})
+	if err != nil {
+		return nil, err
+	}
+	var ret []*GroupNotice
+	err = json.Unmarshal([]byte(result.Raw), &ret)
+	return ret, err
+}
+
+// MarkMessageAsRead 标记消息已读
+func (b *Bot) MarkMessageAsRead(messageId int64) error {
+	_, err := b.requestExtension("mark_msg_as_read", &struct {
+		MessageId int64 `json:"message_id"`
+	}{messageId})
+	return err
+}
+
+// AtAllRemain 群 @全体成员 剩余次数
+type AtAllRemain struct {
+	CanAtAll                 bool  `json:"can_at_all"`                    // 是否可以 @全体成员
+	RemainAtAllCountForGroup int32 `json:"remain_at_all_count_for_group"` // 群内所有管理当天剩余 @全体成员 次数
+	RemainAtAllCountForUin   int32 `json:"remain_at_all_count_for_uin"`   // 机器人当天剩余 @全体成员 次数
+}
+
+// GetGroupAtAllRemain 获取群 @全体成员 剩余次数
+func (b *Bot) GetGroupAtAllRemain(groupId int64) (*AtAllRemain, error) {
+	result, err := b.requestExtension("get_group_at_all_remain", &struct {
+		GroupId int64 `json:"group_id"`
+	}{groupId})
+	if err != nil {
+		return nil, err
+	}
+	var ret *AtAllRemain
+	err = json.Unmarshal([]byte(result.Raw), &ret)
+	return ret, err
+}
+
+// SetGroupPortrait 设置群头像，file-图片，支持的格式同 Image 的 File 字段，cache-是否使用已缓存的文件
+func (b *Bot) SetGroupPortrait(groupId int64, file string, cache bool) error {
+	c := 0
+	if cache {
+		c = 1
+	}
+	_, err := b.requestExtension("set_group_portrait", &struct {
+		GroupId int64  `json:"group_id"`
+		File    string `json:"file"`
+		Cache   int    `json:"cache"`
+	}{groupId, file, c})
+	return err
+}
+
+// GroupPoke 群里戳一戳
+func (b *Bot) GroupPoke(groupId, userId int64) error {
+	_, err := b.requestExtension("group_poke", &struct {
+		GroupId int64 `json:"group_id"`
+		UserId  int64 `json:"user_id"`
+	}{groupId, userId})
+	return err
+}
+
+// FriendPoke 私聊戳一戳
+func (b *Bot) FriendPoke(userId int64) error {
+	_, err := b.requestExtension("friend_poke", &struct {
+		UserId int64 `json:"user_id"`
+	}{userId})
+	return err
+}
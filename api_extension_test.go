@@ -0,0 +1,87 @@
+package onebot
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestApiExtensionConformance 检查扩展API的 action 和参数是否和 go-cqhttp 、 NapCat 一致
+func TestApiExtensionConformance(t *testing.T) {
+	nodes := []*Node{{Id: "1"}, {UserId: "2", Nickname: "a", Content: MessageChain{&Text{Text: "hi"}}}}
+	const nodesJson = `[{"type":"node","data":{"id":"1"}},` +
+		`{"type":"node","data":{"user_id":"2","nickname":"a","content":[{"type":"text","data":{"text":"hi"}}]}}]`
+	tests := []struct {
+		name   string
+		call   func(b *Bot) error
+		action string
+		params string
+		data   string
+	}{
+		{"SendGroupForwardMessage", func(b *Bot) error { _, _, err := b.SendGroupForwardMessage(2, nodes); return err },
+			"send_group_forward_msg", `{"group_id":2,"messages":` + nodesJson + `}`, `{"message_id":1,"forward_id":"abc"}`},
+		{"SendPrivateForwardMessage", func(b *Bot) error { _, _, err := b.SendPrivateForwardMessage(1, nodes); return err },
+			"send_private_forward_msg", `{"user_id":1,"messages":` + nodesJson + `}`, `{"message_id":1,"forward_id":"abc"}`},
+		{"GetGroupMessageHistory", func(b *Bot) error { _, err := b.GetGroupMessageHistory(2, 0); return err },
+			"get_group_msg_history", `{"group_id":2}`, `{"messages":[]}`},
+		{"SetEssenceMessage", func(b *Bot) error { return b.SetEssenceMessage(3) },
+			"set_essence_msg", `{"message_id":3}`, ""},
+		{"DeleteEssenceMessage", func(b *Bot) error { return b.DeleteEssenceMessage(3) },
+			"delete_essence_msg", `{"message_id":3}`, ""},
+		{"GetEssenceMessageList", func(b *Bot) error { _, err := b.GetEssenceMessageList(2); return err },
+			"get_essence_msg_list", `{"group_id":2}`, `[]`},
+		{"SendGroupNotice", func(b *Bot) error { return b.SendGroupNotice(2, "content", "") },
+			"_send_group_notice", `{"group_id":2,"content":"content"}`, ""},
+		{"GetGroupNotice", func(b *Bot) error { _, err := b.GetGroupNotice(2); return err },
+			"_get_group_notice", `{"group_id":2}`, `[]`},
+		{"MarkMessageAsRead", func(b *Bot) error { return b.MarkMessageAsRead(3) },
+			"mark_msg_as_read", `{"message_id":3}`, ""},
+		{"GetGroupAtAllRemain", func(b *Bot) error { _, err := b.GetGroupAtAllRemain(2); return err },
+			"get_group_at_all_remain", `{"group_id":2}`, `{"can_at_all":true}`},
+		{"SetGroupPortrait", func(b *Bot) error { return b.SetGroupPortrait(2, "file:///a.png", true) },
+			"set_group_portrait", `{"group_id":2,"file":"file:///a.png","cache":1}`, ""},
+		{"GroupPoke", func(b *Bot) error { return b.GroupPoke(2, 1) },
+			"group_poke", `{"group_id":2,"user_id":1}`, ""},
+		{"FriendPoke", func(b *Bot) error { return b.FriendPoke(1) },
+			"friend_poke", `{"user_id":1}`, ""},
+	}
+	b := newBot(0, true, defaultWriteTimeout)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &captureTransport{data: tt.data}
+			b.SetTransport(transport)
+			if err := tt.call(b); err != nil {
+				t.Fatal(err)
+			}
+			if transport.action != tt.action {
+				t.Fatal("action:", transport.action)
+			}
+			if !jsonEqual(transport.params, []byte(tt.params)) {
+				t.Fatal("params:", string(transport.params))
+			}
+		})
+	}
+}
+
+func TestApiExtensionUnsupported(t *testing.T) {
+	b := newBot(0, true, defaultWriteTimeout)
+	transport := &captureTransport{retcode: RetcodeUnsupportedAction}
+	b.SetTransport(transport)
+	if !b.IsSupported("group_poke") {
+		t.Fatal("should be supported before calling")
+	}
+	if err := b.GroupPoke(2, 1); !errors.Is(err, ErrUnsupportedAction) {
+		t.Fatal(err)
+	}
+	if b.IsSupported("group_poke") {
+		t.Fatal("should not be supported")
+	}
+	// 之后不再发送请求
+	if err := b.GroupPoke(2, 1); !errors.Is(err, ErrUnsupportedAction) || transport.calls != 1 {
+		t.Fatal(err, transport.calls)
+	}
+	// 其它错误不影响
+	transport.retcode = RetcodeFailed
+	if err := b.FriendPoke(1); err == nil || errors.Is(err, ErrUnsupportedAction) || !b.IsSupported("friend_poke") {
+		t.Fatal(err)
+	}
+}
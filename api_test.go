@@ -0,0 +1,172 @@
+package onebot
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+// captureTransport 记录API调用的 action 和参数，并返回固定的 data
+type captureTransport struct {
+	action  string
+	params  []byte
+	data    string
+	retcode int64 // 不为0时返回失败
+	calls   int
+}
+
+func (t *captureTransport) Call(_ context.Context, action string, params any) (gjson.Result, error) {
+	t.action = action
+	t.calls++
+	t.params = []byte("null")
+	if params != nil {
+		buf, err := json.Marshal(params)
+		if err != nil {
+			return gjson.Result{}, err
+		}
+		t.params = buf
+	}
+	if t.retcode != 0 {
+		return gjson.Parse(`{"status":"failed","retcode":` + strconv.FormatInt(t.retcode, 10) + `,"data":null}`), nil
+	}
+	data := t.data
+	if len(data) == 0 {
+		data = "null"
+	}
+	return gjson.Parse(`{"status":"ok","retcode":0,"data":` + data + `}`), nil
+}
+
+func jsonEqual(a, b []byte) bool {
+	var va, vb any
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// TestApiConformance 检查每个API的 action 和参数是否符合 OneBot 11 标准
+func TestApiConformance(t *testing.T) {
+	chain := MessageChain{&Text{Text: "hi"}, &Face{Id: "178"}}
+	const chainJson = `[{"type":"text","data":{"text":"hi"}},{"type":"face","data":{"id":"178"}}]`
+	tests := []struct {
+		name   string
+		call   func(b *Bot) error
+		action string
+		params string
+		data   string
+	}{
+		{"SendPrivateMessage", func(b *Bot) error { _, err := b.SendPrivateMessage(1, chain); return err },
+			"send_private_msg", `{"user_id":1,"message":` + chainJson + `}`, `{"message_id":1}`},
+		{"SendGroupMessage", func(b *Bot) error { _, err := b.SendGroupMessage(2, chain); return err },
+			"send_group_msg", `{"group_id":2,"message":` + chainJson + `}`, `{"message_id":1}`},
+		{"SendPrivateRawMessage", func(b *Bot) error { _, err := b.SendPrivateRawMessage(1, "[CQ:face,id=178]", true); return err },
+			"send_private_msg", `{"user_id":1,"message":"[CQ:face,id=178]","auto_escape":true}`, `{"message_id":1}`},
+		{"SendGroupRawMessage", func(b *Bot) error { _, err := b.SendGroupRawMessage(2, "hi", false); return err },
+			"send_group_msg", `{"group_id":2,"message":"hi","auto_escape":false}`, `{"message_id":1}`},
+		{"SendMessage", func(b *Bot) error { _, err := b.SendMessage(MessageTypeGroup, 2, chain); return err },
+			"send_msg", `{"message_type":"group","group_id":2,"message":` + chainJson + `}`, `{"message_id":1}`},
+		{"DeleteMessage", func(b *Bot) error { return b.DeleteMessage(3) },
+			"delete_msg", `{"message_id":3}`, ""},
+		{"GetMessage", func(b *Bot) error { _, err := b.GetMessage(3); return err },
+			"get_msg", `{"message_id":3}`, `{"message_id":3,"message":[]}`},
+		{"GetForwardMessage", func(b *Bot) error { _, err := b.GetForwardMessage("abc"); return err },
+			"get_forward_msg", `{"id":"abc"}`, `{"messages":[]}`},
+		{"SendLike", func(b *Bot) error { return b.SendLike(1, 10) },
+			"send_like", `{"user_id":1,"times":10}`, ""},
+		{"SetGroupKick", func(b *Bot) error { return b.SetGroupKick(2, 1, true) },
+			"set_group_kick", `{"group_id":2,"user_id":1,"reject_add_request":true}`, ""},
+		{"SetGroupBan", func(b *Bot) error { return b.SetGroupBan(2, 1, 60) },
+			"set_group_ban", `{"group_id":2,"user_id":1,"duration":60}`, ""},
+		{"SetGroupAnonymousBan", func(b *Bot) error { return b.SetGroupAnonymousBan(2, "flag", 60) },
+			"set_group_anonymous_ban", `{"group_id":2,"anonymous_flag":"flag","duration":60}`, ""},
+		{"SetGroupWholeBan", func(b *Bot) error { return b.SetGroupWholeBan(2, true) },
+			"set_group_whole_ban", `{"group_id":2,"enable":true}`, ""},
+		{"SetGroupAdmin", func(b *Bot) error { return b.SetGroupAdmin(2, 1, false) },
+			"set_group_admin", `{"group_id":2,"user_id":1,"enable":false}`, ""},
+		{"SetGroupAnonymous", func(b *Bot) error { return b.SetGroupAnonymous(2, true) },
+			"set_group_anonymous", `{"group_id":2,"enable":true}`, ""},
+		{"SetGroupCard", func(b *Bot) error { return b.SetGroupCard(2, 1, "card") },
+			"set_group_card", `{"group_id":2,"user_id":1,"card":"card"}`, ""},
+		{"SetGroupName", func(b *Bot) error { return b.SetGroupName(2, "name") },
+			"set_group_name", `{"group_id":2,"group_name":"name"}`, ""},
+		{"SetGroupLeave", func(b *Bot) error { return b.SetGroupLeave(2, true) },
+			"set_group_leave", `{"group_id":2,"is_dismiss":true}`, ""},
+		{"SetGroupSpecialTitle", func(b *Bot) error { return b.SetGroupSpecialTitle(2, 1, "title", -1) },
+			"set_group_special_title", `{"group_id":2,"user_id":1,"special_title":"title","duration":-1}`, ""},
+		{"SetFriendAddRequest", func(b *Bot) error { return b.SetFriendAddRequest("flag", true, "remark") },
+			"set_friend_add_request", `{"flag":"flag","approve":true,"remark":"remark"}`, ""},
+		{"SetGroupAddRequest", func(b *Bot) error { return b.SetGroupAddRequest("flag", GroupRequestInvite, false, "reason") },
+			"set_group_add_request", `{"flag":"flag","sub_type":"invite","approve":false,"reason":"reason"}`, ""},
+		{"GetLoginInfo", func(b *Bot) error { _, err := b.GetLoginInfo(); return err },
+			"get_login_info", `null`, `{"user_id":1,"nickname":"bot"}`},
+		{"GetStrangerInfo", func(b *Bot) error { _, err := b.GetStrangerInfo(1, true); return err },
+			"get_stranger_info", `{"user_id":1,"no_cache":true}`, `{"user_id":1}`},
+		{"GetFriendList", func(b *Bot) error { _, err := b.GetFriendList(); return err },
+			"get_friend_list", `null`, `[]`},
+		{"GetGroupInfo", func(b *Bot) error { _, err := b.GetGroupInfo(2, false); return err },
+			"get_group_info", `{"group_id":2}`, `{"group_id":2}`},
+		{"GetGroupList", func(b *Bot) error { _, err := b.GetGroupList(); return err },
+			"get_group_list", `null`, `[]`},
+		{"GetGroupMemberInfo", func(b *Bot) error { _, err := b.GetGroupMemberInfo(2, 1, true); return err },
+			"get_group_member_info", `{"group_id":2,"user_id":1,"no_cache":true}`, `{"group_id":2,"user_id":1}`},
+		{"GetGroupMemberList", func(b *Bot) error { _, err := b.GetGroupMemberList(2); return err },
+			"get_group_member_list", `{"group_id":2}`, `[]`},
+		{"GetGroupHonorInfo", func(b *Bot) error { _, err := b.GetGroupHonorInfo(2, HonorTypeAll); return err },
+			"get_group_honor_info", `{"group_id":2,"type":"all"}`, `{"group_id":2}`},
+		{"GetCookies", func(b *Bot) error { _, err := b.GetCookies("qun.qq.com"); return err },
+			"get_cookies", `{"domain":"qun.qq.com"}`, `{"cookies":""}`},
+		{"GetCsrfToken", func(b *Bot) error { _, err := b.GetCsrfToken(); return err },
+			"get_csrf_token", `null`, `{"token":1}`},
+		{"GetCredentials", func(b *Bot) error { _, _, err := b.GetCredentials("qun.qq.com"); return err },
+			"get_credentials", `{"domain":"qun.qq.com"}`, `{"cookies":"","token":1}`},
+		{"GetRecord", func(b *Bot) error { _, err := b.GetRecord("1.amr", "mp3"); return err },
+			"get_record", `{"file":"1.amr","out_format":"mp3"}`, `{"file":"1.mp3"}`},
+		{"GetImage", func(b *Bot) error { _, err := b.GetImage("1.image"); return err },
+			"get_image", `{"file":"1.image"}`, `{"file":"1.jpg"}`},
+		{"CanSendImage", func(b *Bot) error { _, err := b.CanSendImage(); return err },
+			"can_send_image", `null`, `{"yes":true}`},
+		{"CanSendRecord", func(b *Bot) error { _, err := b.CanSendRecord(); return err },
+			"can_send_record", `null`, `{"yes":true}`},
+		{"GetStatus", func(b *Bot) error { _, err := b.GetStatus(); return err },
+			"get_status", `null`, `{"online":true,"good":true}`},
+		{"GetVersionInfo", func(b *Bot) error { _, err := b.GetVersionInfo(); return err },
+			"get_version_info", `null`, `{"app_name":"test"}`},
+		{"SetRestart", func(b *Bot) error { return b.SetRestart(2000) },
+			"set_restart", `{"delay":2000}`, ""},
+		{"CleanCache", func(b *Bot) error { return b.CleanCache() },
+			"clean_cache", `null`, ""},
+	}
+	b := newBot(0, true, defaultWriteTimeout)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &captureTransport{data: tt.data}
+			b.SetTransport(transport)
+			if err := tt.call(b); err != nil {
+				t.Fatal(err)
+			}
+			if transport.action != tt.action {
+				t.Fatal("action:", transport.action)
+			}
+			if !jsonEqual(transport.params, []byte(tt.params)) {
+				t.Fatal("params:", string(transport.params))
+			}
+		})
+	}
+}
+
+func TestGetGroupHonorInfo(t *testing.T) {
+	b := newBot(0, true, defaultWriteTimeout)
+	b.SetTransport(&captureTransport{data: `{"group_id":2,"current_talkative":{"user_id":1,"nickname":"a","day_count":3},` +
+		`"talkative_list":[{"user_id":1,"nickname":"a","description":"desc"}],"emotion_list":[]}`})
+	info, err := b.GetGroupHonorInfo(2, HonorTypeAll)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.GroupId != 2 || info.CurrentTalkative.DayCount != 3 || len(info.TalkativeList) != 1 || info.TalkativeList[0].Description != "desc" {
+		t.Fatal(info)
+	}
+}
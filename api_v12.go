@@ -0,0 +1,229 @@
+package onebot
+
+import "encoding/json"
+
+// 本文件是 OneBot 12 的API，需要用 WithProtocolVersion(ProtocolV12) 连接。
+// 与 OneBot 11 同名的API以 V12 结尾，OneBot 12 独有的API不加后缀。
+
+// MessageTargetV12 发送消息的目标（OneBot 12），根据 DetailType 填写对应的字段
+type MessageTargetV12 struct {
+	DetailType string `json:"detail_type"`          // "private"、"group"、"channel"或扩展的类型
+	UserId     string `json:"user_id,omitempty"`    // 用户 ID，私聊时需要
+	GroupId    string `json:"group_id,omitempty"`   // 群 ID，群聊时需要
+	GuildId    string `json:"guild_id,omitempty"`   // 群组 ID，频道消息时需要
+	ChannelId  string `json:"channel_id,omitempty"` // 频道 ID，频道消息时需要
+}
+
+// SendMessageV12 发送消息（OneBot 12），返回消息ID
+func (b *Bot) SendMessageV12(target *MessageTargetV12, message MessageChainV12) (string, error) {
+	result, err := b.request("send_message", &struct {
+		*MessageTargetV12
+		Message MessageChainV12 `json:"message"`
+	}{target, message})
+	if err != nil {
+		return "", err
+	}
+	return result.Get("message_id").String(), nil
+}
+
+// DeleteMessageV12 撤回消息（OneBot 12）
+func (b *Bot) DeleteMessageV12(messageId string) error {
+	_, err := b.request("delete_message", &struct {
+		MessageId string `json:"message_id"`
+	}{messageId})
+	return err
+}
+
+// GetSupportedActions 获取OneBot实现支持的所有API（OneBot 12）
+func (b *Bot) GetSupportedActions() ([]string, error) {
+	result, err := b.request("get_supported_actions", nil)
+	if err != nil {
+		return nil, err
+	}
+	var actions []string
+	err = json.Unmarshal([]byte(result.Raw), &actions)
+	return actions, err
+}
+
+// GetStatusV12 获取运行状态（OneBot 12）
+func (b *Bot) GetStatusV12() (*StatusV12, error) {
+	result, err := b.request("get_status", nil)
+	if err != nil {
+		return nil, err
+	}
+	var ret *StatusV12
+	err = json.Unmarshal([]byte(result.Raw), &ret)
+	return ret, err
+}
+
+// GetVersionV12 获取版本信息（OneBot 12）
+func (b *Bot) GetVersionV12() (*VersionInfoV12, error) {
+	result, err := b.request("get_version", nil)
+	if err != nil {
+		return nil, err
+	}
+	var ret *VersionInfoV12
+	err = json.Unmarshal([]byte(result.Raw), &ret)
+	return ret, err
+}
+
+// UserInfoV12 用户信息（OneBot 12）
+type UserInfoV12 struct {
+	UserId          string `json:"user_id"`          // 用户 ID
+	UserName        string `json:"user_name"`        // 用户名称／昵称
+	UserDisplayname string `json:"user_displayname"` // 用户设置的显示名称，例如群名片，没有时为空字符串
+	UserRemark      string `json:"user_remark"`      // 机器人账号对该用户的备注名称，没有时为空字符串
+}
+
+// GetSelfInfo 获取机器人自身信息（OneBot 12）
+func (b *Bot) GetSelfInfo() (*UserInfoV12, error) {
+	result, err := b.request("get_self_info", nil)
+	if err != nil {
+		return nil, err
+	}
+	var ret *UserInfoV12
+	err = json.Unmarshal([]byte(result.Raw), &ret)
+	return ret, err
+}
+
+// GetUserInfo 获取用户信息（OneBot 12）
+func (b *Bot) GetUserInfo(userId string) (*UserInfoV12, error) {
+	result, err := b.request("get_user_info", &struct {
+		UserId string `json:"user_id"`
+	}{userId})
+	if err != nil {
+		return nil, err
+	}
+	var ret *UserInfoV12
+	err = json.Unmarshal([]byte(result.Raw), &ret)
+	return ret, err
+}
+
+// GetFriendListV12 获取好友列表（OneBot 12）
+func (b *Bot) GetFriendListV12() ([]*UserInfoV12, error) {
+	result, err := b.request("get_friend_list", nil)
+	if err != nil {
+		return nil, err
+	}
+	var ret []*UserInfoV12
+	err = json.Unmarshal([]byte(result.Raw), &ret)
+	return ret, err
+}
+
+// GroupInfoV12 群信息（OneBot 12）
+type GroupInfoV12 struct {
+	GroupId   string `json:"group_id"`   // 群 ID
+	GroupName string `json:"group_name"` // 群名称
+}
+
+// GetGroupInfoV12 获取群信息（OneBot 12）
+func (b *Bot) GetGroupInfoV12(groupId string) (*GroupInfoV12, error) {
+	result, err := b.request("get_group_info", &struct {
+		GroupId string `json:"group_id"`
+	}{groupId})
+	if err != nil {
+		return nil, err
+	}
+	var ret *GroupInfoV12
+	err = json.Unmarshal([]byte(result.Raw), &ret)
+	return ret, err
+}
+
+// GetGroupListV12 获取群列表（OneBot 12）
+func (b *Bot) GetGroupListV12() ([]*GroupInfoV12, error) {
+	result, err := b.request("get_group_list", nil)
+	if err != nil {
+		return nil, err
+	}
+	var ret []*GroupInfoV12
+	err = json.Unmarshal([]byte(result.Raw), &ret)
+	return ret, err
+}
+
+// GetGroupMemberInfoV12 获取群成员信息（OneBot 12）
+func (b *Bot) GetGroupMemberInfoV12(groupId, userId string) (*UserInfoV12, error) {
+	result, err := b.request("get_group_member_info", &struct {
+		GroupId string `json:"group_id"`
+		UserId  string `json:"user_id"`
+	}{groupId, userId})
+	if err != nil {
+		return nil, err
+	}
+	var ret *UserInfoV12
+	err = json.Unmarshal([]byte(result.Raw), &ret)
+	return ret, err
+}
+
+// GetGroupMemberListV12 获取群成员列表（OneBot 12）
+func (b *Bot) GetGroupMemberListV12(groupId string) ([]*UserInfoV12, error) {
+	result, err := b.request("get_group_member_list", &struct {
+		GroupId string `json:"group_id"`
+	}{groupId})
+	if err != nil {
+		return nil, err
+	}
+	var ret []*UserInfoV12
+	err = json.Unmarshal([]byte(result.Raw), &ret)
+	return ret, err
+}
+
+// SetGroupNameV12 设置群名称（OneBot 12）
+func (b *Bot) SetGroupNameV12(groupId, groupName string) error {
+	_, err := b.request("set_group_name", &struct {
+		GroupId   string `json:"group_id"`
+		GroupName string `json:"group_name"`
+	}{groupId, groupName})
+	return err
+}
+
+// LeaveGroup 退出群（OneBot 12）
+func (b *Bot) LeaveGroup(groupId string) error {
+	_, err := b.request("leave_group", &struct {
+		GroupId string `json:"group_id"`
+	}{groupId})
+	return err
+}
+
+// UploadFileParams 上传文件的参数（OneBot 12），根据 Type 填写对应的字段
+type UploadFileParams struct {
+	Type    string            `json:"type"`              // 上传文件的方式，"url"、"path"或"data"
+	Name    string            `json:"name"`              // 文件名
+	Url     string            `json:"url,omitempty"`     // 文件 URL，Type 为"url"时需要
+	Headers map[string]string `json:"headers,omitempty"` // 下载文件时使用的HTTP头，Type 为"url"时可选
+	Path    string            `json:"path,omitempty"`    // 文件路径，Type 为"path"时需要
+	Data    []byte            `json:"data,omitempty"`    // 文件内容，Type 为"data"时需要，会被编码为Base64
+	Sha256  string            `json:"sha256,omitempty"`  // 文件数据的 SHA256 校验和，全小写，可选
+}
+
+// UploadFile 上传文件（OneBot 12），返回的文件ID可以用于 ImageV12 、 Voice 、 FileV12 等消息段
+func (b *Bot) UploadFile(params *UploadFileParams) (string, error) {
+	result, err := b.request("upload_file", params)
+	if err != nil {
+		return "", err
+	}
+	return result.Get("file_id").String(), nil
+}
+
+// FileInfoV12 文件信息（OneBot 12），根据获取时的 type 参数，只有对应的字段有值
+type FileInfoV12 struct {
+	Name    string            `json:"name"`              // 文件名
+	Url     string            `json:"url,omitempty"`     // 文件 URL
+	Headers map[string]string `json:"headers,omitempty"` // 下载文件时需要使用的HTTP头
+	Path    string            `json:"path,omitempty"`    // 文件路径
+	Data    []byte            `json:"data,omitempty"`    // 文件内容
+	Sha256  string            `json:"sha256,omitempty"`  // 文件数据的 SHA256 校验和
+}
+
+// GetFile 获取文件（OneBot 12），fileType 为"url"、"path"或"data"
+func (b *Bot) GetFile(fileId, fileType string) (*FileInfoV12, error) {
+	result, err := b.request("get_file", &struct {
+		FileId string `json:"file_id"`
+		Type   string `json:"type"`
+	}{fileId, fileType})
+	if err != nil {
+		return nil, err
+	}
+	var ret *FileInfoV12
+	err = json.Unmarshal([]byte(result.Raw), &ret)
+	return ret, err
+}
@@ -0,0 +1,305 @@
+package onebot
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/tidwall/gjson"
+)
+
+// ConnState 连接状态
+type ConnState int32
+
+const (
+	StateConnecting   ConnState = iota // 正在进行首次连接
+	StateConnected                     // 已连接
+	StateDisconnected                  // 连接已断开，等待重新连接（反向WebSocket由OneBot实现负责重连）
+	StateReconnecting                  // 正在重连
+	StateClosed                        // 已关闭，不会再重连
+	StateNoWebSocket                   // 没有使用WebSocket连接，例如 NewHttpBot 创建的 Bot
+)
+
+func (s ConnState) String() string {
+	switch s {
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateDisconnected:
+		return "disconnected"
+	case StateReconnecting:
+		return "reconnecting"
+	case StateClosed:
+		return "closed"
+	case StateNoWebSocket:
+		return "no websocket"
+	default:
+		return "unknown"
+	}
+}
+
+// ReconnectPolicy 断线重连策略，每次重连失败后，等待时间乘以 Multiplier ，直到 MaxInterval
+type ReconnectPolicy struct {
+	InitialInterval time.Duration // 第一次重连前的等待时间
+	MaxInterval     time.Duration // 最长等待时间，0表示不限
+	Multiplier      float64       // 每次失败后等待时间的倍数，小于1时按1处理
+	Jitter          float64       // 随机抖动的比例，取值0~1，例如0.2表示在±20%的范围内随机
+	MaxAttempts     int           // 连续重连失败多少次后放弃并关闭 Bot ，0表示不限
+}
+
+// DefaultReconnectPolicy 默认的断线重连策略
+var DefaultReconnectPolicy = ReconnectPolicy{
+	InitialInterval: time.Second,
+	MaxInterval:     time.Minute,
+	Multiplier:      2,
+	Jitter:          0.2,
+}
+
+// interval 第 attempt 次（从1开始）重连前的等待时间
+func (p *ReconnectPolicy) interval(attempt int) time.Duration {
+	d := float64(p.InitialInterval)
+	for i := 1; i < attempt && (p.MaxInterval <= 0 || d < float64(p.MaxInterval)); i++ {
+		d *= max(p.Multiplier, 1)
+	}
+	if p.MaxInterval > 0 {
+		d = min(d, float64(p.MaxInterval))
+	}
+	if p.Jitter > 0 {
+		d *= 1 + p.Jitter*(2*rand.Float64()-1) // nolint:gosec
+	}
+	return time.Duration(d)
+}
+
+// defaultWriteTimeout 默认的写超时时间
+const defaultWriteTimeout = 10 * time.Second
+
+// outbound 等待写入的一条消息
+type outbound struct {
+	buf    []byte
+	result chan error
+}
+
+// writer 是一条WebSocket连接唯一的写入者。 websocket.Conn 不支持并发写，所以所有消息都要通过它排队写入
+type writer struct {
+	c       *websocket.Conn
+	timeout time.Duration
+	queue   chan *outbound
+	stopped chan struct{}
+	once    sync.Once
+}
+
+func newWriter(c *websocket.Conn, timeout time.Duration) *writer {
+	w := &writer{
+		c:       c,
+		timeout: timeout,
+		queue:   make(chan *outbound, 64),
+		stopped: make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+func (w *writer) run() {
+	for {
+		select {
+		case <-w.stopped:
+			return
+		case m := <-w.queue:
+			if w.timeout > 0 {
+				_ = w.c.SetWriteDeadline(time.Now().Add(w.timeout))
+			}
+			err := w.c.WriteMessage(websocket.TextMessage, m.buf)
+			m.result <- err
+			if err != nil {
+				// 写失败后连接已经不可用，关闭它，让读循环发现断线并重连
+				w.stop()
+				_ = w.c.Close()
+				return
+			}
+		}
+	}
+}
+
+func (w *writer) stop() {
+	w.once.Do(func() { close(w.stopped) })
+}
+
+// write 把消息放入队列，并等待写入完成
+func (w *writer) write(ctx context.Context, buf []byte) error {
+	m := &outbound{buf: buf, result: make(chan error, 1)}
+	select {
+	case w.queue <- m:
+	case <-w.stopped:
+		return ErrDisconnected
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-m.result:
+		return err
+	case <-w.stopped:
+		return ErrDisconnected
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// conn 保存当前可用的WebSocket连接，断线后会被清空，直到重新连接
+type conn struct {
+	lock         sync.RWMutex
+	c            *websocket.Conn
+	w            *writer
+	writeTimeout time.Duration
+	connected    chan struct{} // 已连接时是一个已经关闭的channel，断开后换成新的
+	state        atomic.Int32
+}
+
+func newConn(writeTimeout time.Duration) *conn {
+	return &conn{writeTimeout: writeTimeout, connected: make(chan struct{})}
+}
+
+func (cn *conn) get() *websocket.Conn {
+	cn.lock.RLock()
+	defer cn.lock.RUnlock()
+	return cn.c
+}
+
+func (cn *conn) writer() *writer {
+	cn.lock.RLock()
+	defer cn.lock.RUnlock()
+	return cn.w
+}
+
+func (cn *conn) set(c *websocket.Conn) {
+	cn.lock.Lock()
+	defer cn.lock.Unlock()
+	if cn.w != nil {
+		cn.w.stop()
+	}
+	cn.c = c
+	cn.w = newWriter(c, cn.writeTimeout)
+	cn.state.Store(int32(StateConnected))
+	select {
+	case <-cn.connected:
+	default:
+		close(cn.connected)
+	}
+}
+
+// clear 如果当前连接是 c ，则清空，并把状态设置为 state ，返回是否清空了
+func (cn *conn) clear(c *websocket.Conn, state ConnState) bool {
+	cn.lock.Lock()
+	defer cn.lock.Unlock()
+	if cn.c != c {
+		return false
+	}
+	cn.w.stop()
+	cn.c, cn.w = nil, nil
+	cn.state.Store(int32(state))
+	select {
+	case <-cn.connected:
+		cn.connected = make(chan struct{})
+	default:
+	}
+	return true
+}
+
+// clearConn 清空断开的连接。如果是API连接，则正在等待响应的请求立即返回 ErrDisconnected
+func (b *Bot) clearConn(cn *conn, c *websocket.Conn, state ConnState) {
+	if !cn.clear(c, state) || cn != b.api {
+		return
+	}
+	b.syncIdMap.Range(func(echo, _ any) bool {
+		if ch, ok := b.syncIdMap.LoadAndDelete(echo); ok {
+			close(ch.(chan gjson.Result))
+		}
+		return true
+	})
+}
+
+// wait 等待重新连接成功
+func (cn *conn) wait(ctx context.Context, done <-chan struct{}) (*writer, error) {
+	for {
+		cn.lock.RLock()
+		w, connected := cn.w, cn.connected
+		cn.lock.RUnlock()
+		if w != nil {
+			return w, nil
+		}
+		select {
+		case <-connected:
+		case <-done:
+			return nil, ErrDisconnected
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// keepAlive 不断地从 cn 中读取消息，断线后按照重连策略重新连接，直到 Bot 被关闭
+func (b *Bot) keepAlive(cn *conn, dial func(ctx context.Context) (*websocket.Conn, error), o *options, log *slog.Logger) {
+	attempt := 0
+	for !b.closed.Load() {
+		c := cn.get()
+		if c == nil {
+			attempt++
+			if o.reconnect.MaxAttempts > 0 && attempt > o.reconnect.MaxAttempts {
+				log.Error("reconnect attempts exhausted, give up", "attempts", o.reconnect.MaxAttempts)
+				_ = b.Close()
+				return
+			}
+			delay := o.reconnect.interval(attempt)
+			cn.state.Store(int32(StateReconnecting))
+			if o.onReconnecting != nil {
+				o.onReconnecting(b, attempt, delay)
+			}
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-b.done:
+				timer.Stop()
+				return
+			}
+			log.Info("trying to reconnect", "attempt", attempt)
+			var err error
+			if c, err = dial(context.Background()); err != nil {
+				continue
+			}
+			attempt = 0
+			cn.set(c)
+			if b.closed.Load() {
+				_ = c.Close()
+				b.clearConn(cn, c, StateClosed)
+				return
+			}
+			if o.onConnected != nil {
+				b.runHook(o.onConnected)
+			}
+		}
+		for {
+			t, message, err := c.ReadMessage()
+			if err != nil {
+				log.Error("read error", "error", err)
+				_ = c.Close()
+				if b.closed.Load() {
+					b.clearConn(cn, c, StateClosed)
+					return
+				}
+				b.clearConn(cn, c, StateDisconnected)
+				if o.onDisconnected != nil {
+					o.onDisconnected(b, err)
+				}
+				break
+			}
+			if t != websocket.TextMessage {
+				continue
+			}
+			b.onMessage(log, message)
+		}
+	}
+}
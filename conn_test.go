@@ -0,0 +1,297 @@
+package onebot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/tidwall/gjson"
+)
+
+// newTestServer 启动一个本地WebSocket服务端，每个连接都交给 handler 处理，返回 ws:// 地址
+func newTestServer(t *testing.T, handler func(c *websocket.Conn)) string {
+	var upgrader websocket.Upgrader
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() { _ = c.Close() }()
+		handler(c)
+	}))
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func TestReconnectPolicy(t *testing.T) {
+	p := ReconnectPolicy{InitialInterval: time.Second, MaxInterval: 5 * time.Second, Multiplier: 2}
+	for attempt, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		if d := p.interval(attempt + 1); d != expected {
+			t.Fatal(attempt+1, d)
+		}
+	}
+	p.Jitter = 0.5
+	for range 100 {
+		if d := p.interval(1); d < 500*time.Millisecond || d > 1500*time.Millisecond {
+			t.Fatal(d)
+		}
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	var count atomic.Int32
+	addr := newTestServer(t, func(c *websocket.Conn) {
+		if count.Add(1) == 1 {
+			return // 第一次连接立即断开
+		}
+		for {
+			if _, _, err := c.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+	var connected, disconnected, reconnecting atomic.Int32
+	b, err := ConnectWithOptions(context.Background(), addr,
+		WithReconnectPolicy(ReconnectPolicy{InitialInterval: 10 * time.Millisecond, Multiplier: 1}),
+		WithOnConnected(func(*Bot) { connected.Add(1) }),
+		WithOnDisconnected(func(*Bot, error) { disconnected.Add(1) }),
+		WithOnReconnecting(func(_ *Bot, attempt int, _ time.Duration) { reconnecting.Add(1) }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for connected.Load() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("reconnect timeout")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if disconnected.Load() != 1 || reconnecting.Load() != 1 || b.State() != StateConnected {
+		t.Fatal(disconnected.Load(), reconnecting.Load(), b.State())
+	}
+	_ = b.Close()
+	if b.State() != StateClosed {
+		t.Fatal(b.State())
+	}
+}
+
+func TestOnConnected(t *testing.T) {
+	var count atomic.Int32
+	addr := newTestServer(t, func(c *websocket.Conn) {
+		n := count.Add(1)
+		// 连接成功后立即推送事件，回调中注册的监听者也不会错过
+		event := fmt.Sprintf(`{"post_type":"notice","notice_type":"friend_add","user_id":%d}`, n)
+		if err := c.WriteMessage(websocket.TextMessage, []byte(event)); err != nil {
+			return
+		}
+		_, message, err := c.ReadMessage()
+		if err != nil {
+			return
+		}
+		resp := fmt.Sprintf(`{"status":"ok","retcode":0,"data":{"user_id":%d,"nickname":"bot"},"echo":%d}`, n, gjson.GetBytes(message, "echo").Int())
+		if err = c.WriteMessage(websocket.TextMessage, []byte(resp)); err != nil {
+			return
+		}
+		if n == 1 {
+			return // 第一次连接处理完一个请求后断开
+		}
+		_, _, _ = c.ReadMessage()
+	})
+	type result struct {
+		info   *LoginInfo
+		err    error
+		events chan int64
+	}
+	results := make(chan result, 2)
+	b, err := ConnectWithOptions(context.Background(), addr,
+		WithReconnectInterval(10*time.Millisecond),
+		WithOnConnected(func(b *Bot) {
+			events := make(chan int64, 2)
+			b.ListenFriendAddNotice(func(notice *FriendAddNotice) bool {
+				events <- notice.UserId
+				return true
+			})
+			info, err := b.GetLoginInfo() // 回调中可以调用API
+			results <- result{info, err, events}
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = b.Close() }()
+	for i := range int64(2) {
+		select {
+		case r := <-results:
+			if r.err != nil || r.info.UserId != i+1 {
+				t.Fatal(r.info, r.err)
+			}
+			select {
+			case userId := <-r.events:
+				if userId != i+1 {
+					t.Fatal(userId)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("event not received")
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatal("OnConnected not called")
+		}
+	}
+}
+
+// TestConcurrentRequest 需要用 go test -race 运行，检查并发调用API和并发处理事件时没有数据竞争
+func TestConcurrentRequest(t *testing.T) {
+	addr := newTestServer(t, func(c *websocket.Conn) {
+		for i := 0; ; i++ {
+			_, message, err := c.ReadMessage()
+			if err != nil {
+				return
+			}
+			echo := gjson.GetBytes(message, "echo").Int()
+			resp := fmt.Sprintf(`{"status":"ok","retcode":0,"data":{"message_id":%d},"echo":%d}`, echo, echo)
+			if err = c.WriteMessage(websocket.TextMessage, []byte(resp)); err != nil {
+				return
+			}
+			if i < 20 { // 收到请求说明监听者已经注册，开始推送事件
+				event := fmt.Sprintf(`{"post_type":"message","message_type":"group","message_id":%d,"group_id":1000,"user_id":2000,"message":[]}`, i)
+				if err = c.WriteMessage(websocket.TextMessage, []byte(event)); err != nil {
+					return
+				}
+			}
+		}
+	})
+	b, err := ConnectWithOptions(context.Background(), addr, WithConcurrentEvent(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = b.Close() }()
+	var wg sync.WaitGroup
+	wg.Add(20)
+	b.ListenGroupMessage(func(message *GroupMessage) bool {
+		defer wg.Done()
+		if _, err := b.SendGroupMessage(message.GroupId, MessageChain{&Text{Text: "reply"}}); err != nil {
+			t.Error(err)
+		}
+		return true
+	})
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				if _, err := b.SendPrivateMessage(2000, MessageChain{&Text{Text: "hello"}}); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDualConnection(t *testing.T) {
+	apiAddr := newTestServer(t, func(c *websocket.Conn) {
+		for {
+			_, message, err := c.ReadMessage()
+			if err != nil {
+				return
+			}
+			resp := fmt.Sprintf(`{"status":"ok","retcode":0,"data":{"user_id":1,"nickname":"bot"},"echo":%d}`, gjson.GetBytes(message, "echo").Int())
+			if err = c.WriteMessage(websocket.TextMessage, []byte(resp)); err != nil {
+				return
+			}
+		}
+	})
+	ready := make(chan struct{})
+	eventAddr := newTestServer(t, func(c *websocket.Conn) {
+		<-ready
+		event := `{"post_type":"notice","notice_type":"friend_add","user_id":2000}`
+		if err := c.WriteMessage(websocket.TextMessage, []byte(event)); err != nil {
+			return
+		}
+		_, _, _ = c.ReadMessage()
+	})
+	b, err := ConnectWithOptions(context.Background(), apiAddr, WithEventAddr(eventAddr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = b.Close() }()
+	ch := make(chan int64, 1)
+	b.ListenFriendAddNotice(func(notice *FriendAddNotice) bool {
+		ch <- notice.UserId
+		return true
+	})
+	close(ready)
+	info, err := b.GetLoginInfo()
+	if err != nil || info.Nickname != "bot" {
+		t.Fatal(info, err)
+	}
+	select {
+	case userId := <-ch:
+		if userId != 2000 {
+			t.Fatal(userId)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("event not received")
+	}
+	if b.State() != StateConnected {
+		t.Fatal(b.State())
+	}
+}
+
+func TestProtocolV12(t *testing.T) {
+	addr := newTestServer(t, func(c *websocket.Conn) {
+		_, message, err := c.ReadMessage()
+		if err != nil {
+			return
+		}
+		resp := fmt.Sprintf(`{"status":"ok","retcode":0,"data":["get_supported_actions","send_message"],"message":"","echo":%d}`, gjson.GetBytes(message, "echo").Int())
+		if err = c.WriteMessage(websocket.TextMessage, []byte(resp)); err != nil {
+			return
+		}
+		event := `{"id":"b6e65187","time":1632847927.599013,"type":"message","detail_type":"group","sub_type":"",` +
+			`"self":{"platform":"qq","user_id":"123"},"message_id":"6283","group_id":"12467","user_id":"123456788",` +
+			`"message":[{"type":"mention","data":{"user_id":"123"}},{"type":"text","data":{"text":" hello"}}],"alt_message":"@123 hello"}`
+		if err = c.WriteMessage(websocket.TextMessage, []byte(event)); err != nil {
+			return
+		}
+		_, _, _ = c.ReadMessage()
+	})
+	b, err := ConnectWithOptions(context.Background(), addr, WithProtocolVersion(ProtocolV12))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = b.Close() }()
+	ch := make(chan *GroupMessageV12, 1)
+	b.ListenGroupMessage(func(*GroupMessage) bool {
+		t.Error("OneBot 11 listener should not be called")
+		return true
+	})
+	b.ListenGroupMessageV12(func(message *GroupMessageV12) bool {
+		ch <- message
+		return true
+	})
+	actions, err := b.GetSupportedActions()
+	if err != nil || len(actions) != 2 {
+		t.Fatal(actions, err)
+	}
+	select {
+	case message := <-ch:
+		if message.GroupId != "12467" || message.Self.UserId != "123" || len(message.Message) != 2 {
+			t.Fatal(message)
+		}
+		if mention, ok := message.Message[0].(*Mention); !ok || mention.UserId != "123" {
+			t.Fatal(message.Message[0])
+		}
+	case <-time.After(time.Second):
+		t.Fatal("event not received")
+	}
+}
@@ -0,0 +1,211 @@
+package onebot
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+var (
+	cqUnescaper     = strings.NewReplacer("&#91;", "[", "&#93;", "]", "&#44;", ",", "&amp;", "&")
+	cqTextEscaper   = strings.NewReplacer("&", "&amp;", "[", "&#91;", "]", "&#93;")
+	cqParamEscaper  = strings.NewReplacer("&", "&amp;", "[", "&#91;", "]", "&#93;", ",", "&#44;")
+	messageChainTyp = reflect.TypeOf(MessageChain(nil))
+)
+
+// CQString 把消息链转换为CQ码格式的字符串，与OneBot事件中的 raw_message 格式相同，可以用 ParseCQCode 解析回来。
+//
+// 纯文本部分会转义 & [ ] ，CQ码参数中还会转义逗号，所有非空的字段都会输出
+func (c MessageChain) CQString() string {
+	var sb strings.Builder
+	for _, m := range c {
+		if text, ok := m.(*Text); ok {
+			sb.WriteString(cqTextEscaper.Replace(text.Text))
+		} else {
+			sb.WriteString(cqCode(m))
+		}
+	}
+	return sb.String()
+}
+
+// cqCode 把单个消息段转换为CQ码，按照字段定义的顺序输出所有非空的字段
+func cqCode(m SingleMessage) string {
+	var sb strings.Builder
+	sb.WriteString("[CQ:")
+	sb.WriteString(m.GetMessageType())
+	if raw, ok := m.(*RawSegment); ok {
+		// 未知类型的消息段只输出字符串、数字和布尔类型的参数，按参数名排序
+		params := gjson.ParseBytes(raw.data()).Map()
+		for _, name := range slices.Sorted(maps.Keys(params)) {
+			param := params[name]
+			switch param.Type {
+			case gjson.String, gjson.Number:
+				sb.WriteString("," + name + "=" + cqParamEscaper.Replace(param.String()))
+			case gjson.True:
+				sb.WriteString("," + name + "=1")
+			case gjson.False:
+				sb.WriteString("," + name + "=0")
+			default:
+			}
+		}
+		sb.WriteString("]")
+		return sb.String()
+	}
+	v := reflect.ValueOf(m)
+	for v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Struct {
+		t := v.Type()
+		for i := range t.NumField() {
+			f := t.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if !f.IsExported() || len(name) == 0 || name == "-" {
+				continue
+			}
+			field := v.Field(i)
+			if field.IsZero() {
+				continue
+			}
+			var param string
+			switch {
+			case f.Type == messageChainTyp:
+				param = field.Interface().(MessageChain).CQString()
+			case field.Kind() == reflect.String:
+				param = field.String()
+			case field.Kind() == reflect.Bool:
+				param = "1"
+			case field.CanInt():
+				param = strconv.FormatInt(field.Int(), 10)
+			case field.CanFloat():
+				param = strconv.FormatFloat(field.Float(), 'f', -1, 64)
+			default:
+				continue
+			}
+			sb.WriteString(",")
+			sb.WriteString(name)
+			sb.WriteString("=")
+			sb.WriteString(cqParamEscaper.Replace(param))
+		}
+	}
+	sb.WriteString("]")
+	return sb.String()
+}
+
+// ParseCQCode 把CQ码格式的字符串解析为消息链，例如"你好[CQ:face,id=178]"
+//
+// 纯文本部分会被解析为 Text ，其中的 &amp; &#91; &#93; 会被反转义，CQ码参数中还会反转义 &#44;
+func ParseCQCode(s string) (MessageChain, error) {
+	var ret MessageChain
+	for len(s) > 0 {
+		i := strings.Index(s, "[CQ:")
+		if i < 0 {
+			ret = append(ret, &Text{Text: cqUnescaper.Replace(s)})
+			break
+		}
+		if i > 0 {
+			ret = append(ret, &Text{Text: cqUnescaper.Replace(s[:i])})
+		}
+		s = s[i+len("[CQ:"):]
+		j := strings.IndexByte(s, ']')
+		if j < 0 {
+			return nil, errors.New("unclosed cq code: [CQ:" + s)
+		}
+		m, err := parseSingleCQCode(s[:j])
+		if err != nil {
+			return nil, err
+		}
+		if m != nil {
+			ret = append(ret, m)
+		}
+		s = s[j+1:]
+	}
+	return ret, nil
+}
+
+// parseSingleCQCode 解析去掉了"[CQ:"和"]"的CQ码，例如"face,id=178"。未知类型的CQ码解析为 RawSegment
+func parseSingleCQCode(s string) (SingleMessage, error) {
+	parts := strings.Split(s, ",")
+	singleMessageType := parts[0]
+	if len(singleMessageType) == 0 {
+		return nil, errors.New("empty cq code type")
+	}
+	params := make(map[string]string, len(parts)-1)
+	for _, part := range parts[1:] {
+		k, v, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, errors.New("invalid cq code param: " + part)
+		}
+		params[k] = cqUnescaper.Replace(v)
+	}
+	builder, ok := singleMessageBuilder[singleMessageType]
+	if !ok {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, err
+		}
+		return &RawSegment{Type: singleMessageType, Data: data}, nil
+	}
+	m := builder()
+	if err := setCQParams(reflect.ValueOf(m).Elem(), params); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// setCQParams 按照json标签把CQ码的参数填入消息段的字段中
+func setCQParams(v reflect.Value, params map[string]string) error {
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	t := v.Type()
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		param, ok := params[name]
+		if !ok || !f.IsExported() {
+			continue
+		}
+		field := v.Field(i)
+		if f.Type == messageChainTyp {
+			chain, err := ParseCQCode(param)
+			if err != nil {
+				return err
+			}
+			field.Set(reflect.ValueOf(chain))
+			continue
+		}
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(param)
+		case reflect.Bool:
+			b, err := strconv.ParseBool(param)
+			if err != nil {
+				return errors.New("invalid cq code param " + name + ": " + param)
+			}
+			field.SetBool(b)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n, err := strconv.ParseInt(param, 10, 64)
+			if err != nil {
+				return errors.New("invalid cq code param " + name + ": " + param)
+			}
+			field.SetInt(n)
+		case reflect.Float32, reflect.Float64:
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				return errors.New("invalid cq code param " + name + ": " + param)
+			}
+			field.SetFloat(n)
+		default:
+			slog.Warn("unsupported cq code param type", "name", name, "type", f.Type.String())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package onebot
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrTimeout 请求超时，没有收到OneBot的响应
+	ErrTimeout = errors.New("request timeout")
+
+	// ErrDisconnected 连接已断开，请求没有发送出去
+	ErrDisconnected = errors.New("disconnected")
+
+	// ErrRateLimited 被 Bot.SetLimiter 设置的限流器丢弃
+	ErrRateLimited = errors.New("rate limit exceeded")
+
+	// ErrUnsupportedAction OneBot实现不支持此API，对应 retcode 1404
+	ErrUnsupportedAction = errors.New("unsupported action")
+)
+
+const (
+	RetcodeOk                = 0    // 成功
+	RetcodeAsync             = 1    // 已提交 async 处理
+	RetcodeFailed            = 100  // 操作失败，具体原因见 message 和 wording（go-cqhttp 等实现）
+	RetcodeBadRequest        = 1400 // 请求参数错误
+	RetcodeUnauthorized      = 1401 // 未提供 access token
+	RetcodeForbidden         = 1403 // access token 错误
+	RetcodeUnsupportedAction = 1404 // 不支持的API
+)
+
+// ActionError OneBot返回了表示失败的 retcode 。可以用 errors.As 获取，
+// 对于不支持的API，也可以用 errors.Is(err, ErrUnsupportedAction) 判断
+type ActionError struct {
+	Action  string // 调用的API
+	Retcode int64  // 返回码
+	Status  string // 状态，一般是"failed"
+	Message string // 错误信息
+	Wording string // 对错误信息的描述
+	Echo    int64  // 请求的 echo ，通过HTTP调用时为0
+}
+
+func (e *ActionError) Error() string {
+	s := fmt.Sprintf("action %s failed, retcode: %d", e.Action, e.Retcode)
+	if len(e.Message) > 0 {
+		s += ", message: " + e.Message
+	}
+	if len(e.Wording) > 0 && e.Wording != e.Message {
+		s += ", wording: " + e.Wording
+	}
+	return s
+}
+
+func (e *ActionError) Is(target error) bool {
+	return target == ErrUnsupportedAction && e.Retcode == RetcodeUnsupportedAction
+}
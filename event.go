@@ -64,8 +64,8 @@ func (m *PrivateMessage) Reply(b *Bot, reply MessageChain) error {
 }
 
 // ListenPrivateMessage 监听私聊消息
-func (b *Bot) ListenPrivateMessage(l func(message *PrivateMessage) bool) {
-	listen(b, "message", "private", l)
+func (b *Bot) ListenPrivateMessage(l func(message *PrivateMessage) bool) *Listener {
+	return listen(b, "message", "private", l)
 }
 
 type GroupMessageSubType string
@@ -131,8 +131,8 @@ func (m *GroupMessage) Ban(b *Bot, duration int32) error {
 }
 
 // ListenGroupMessage 监听群消息
-func (b *Bot) ListenGroupMessage(l func(message *GroupMessage) bool) {
-	listen(b, "message", "group", l)
+func (b *Bot) ListenGroupMessage(l func(message *GroupMessage) bool) *Listener {
+	return listen(b, "message", "group", l)
 }
 
 // FriendRequest 加好友请求
@@ -155,8 +155,8 @@ func (r *FriendRequest) Reply(b *Bot, approve bool, remark string) error {
 }
 
 // ListenFriendRequest 监听加好友请求
-func (b *Bot) ListenFriendRequest(l func(request *FriendRequest) bool) {
-	listen(b, "request", "friend", l)
+func (b *Bot) ListenFriendRequest(l func(request *FriendRequest) bool) *Listener {
+	return listen(b, "request", "friend", l)
 }
 
 type GroupRequestSubType string
@@ -188,8 +188,8 @@ func (r *GroupRequest) Reply(b *Bot, approve bool, reason string) error {
 }
 
 // ListenGroupRequest 监听加群请求 / 邀请
-func (b *Bot) ListenGroupRequest(l func(request *GroupRequest) bool) {
-	listen(b, "request", "group", l)
+func (b *Bot) ListenGroupRequest(l func(request *GroupRequest) bool) *Listener {
+	return listen(b, "request", "group", l)
 }
 
 type LifecycleMetaEventSubType string
@@ -210,8 +210,8 @@ type LifecycleMetaEvent struct {
 }
 
 // ListenLifecycleMetaEvent 监听生命周期
-func (b *Bot) ListenLifecycleMetaEvent(l func(notice *LifecycleMetaEvent) bool) {
-	listen(b, "meta_event", "lifecycle", l)
+func (b *Bot) ListenLifecycleMetaEvent(l func(notice *LifecycleMetaEvent) bool) *Listener {
+	return listen(b, "meta_event", "lifecycle", l)
 }
 
 // HeartbeatMetaEvent 心跳事件
@@ -225,8 +225,8 @@ type HeartbeatMetaEvent struct {
 }
 
 // ListenHeartbeatMetaEvent 监听心跳事件
-func (b *Bot) ListenHeartbeatMetaEvent(l func(notice *HeartbeatMetaEvent) bool) {
-	listen(b, "meta_event", "heartbeat", l)
+func (b *Bot) ListenHeartbeatMetaEvent(l func(notice *HeartbeatMetaEvent) bool) *Listener {
+	return listen(b, "meta_event", "heartbeat", l)
 }
 
 type File struct {
@@ -248,8 +248,8 @@ type GroupUploadNotice struct {
 }
 
 // ListenGroupUploadNotice 监听群文件上传
-func (b *Bot) ListenGroupUploadNotice(l func(notice *GroupUploadNotice) bool) {
-	listen(b, "notice", "group_upload", l)
+func (b *Bot) ListenGroupUploadNotice(l func(notice *GroupUploadNotice) bool) *Listener {
+	return listen(b, "notice", "group_upload", l)
 }
 
 type GroupAdminNoticeSubType string
@@ -271,8 +271,8 @@ type GroupAdminNotice struct {
 }
 
 // ListenGroupAdminNotice 监听群管理员变动
-func (b *Bot) ListenGroupAdminNotice(l func(notice *GroupAdminNotice) bool) {
-	listen(b, "notice", "group_admin", l)
+func (b *Bot) ListenGroupAdminNotice(l func(notice *GroupAdminNotice) bool) *Listener {
+	return listen(b, "notice", "group_admin", l)
 }
 
 type GroupDecreaseNoticeSubType string
@@ -296,8 +296,8 @@ type GroupDecreaseNotice struct {
 }
 
 // ListenGroupDecreaseNotice 监听群成员减少
-func (b *Bot) ListenGroupDecreaseNotice(l func(notice *GroupDecreaseNotice) bool) {
-	listen(b, "notice", "group_decrease", l)
+func (b *Bot) ListenGroupDecreaseNotice(l func(notice *GroupDecreaseNotice) bool) *Listener {
+	return listen(b, "notice", "group_decrease", l)
 }
 
 type GroupIncreaseNoticeSubType string
@@ -320,8 +320,8 @@ type GroupIncreaseNotice struct {
 }
 
 // ListenGroupIncreaseNotice 监听群成员增加
-func (b *Bot) ListenGroupIncreaseNotice(l func(notice *GroupIncreaseNotice) bool) {
-	listen(b, "notice", "group_increase", l)
+func (b *Bot) ListenGroupIncreaseNotice(l func(notice *GroupIncreaseNotice) bool) *Listener {
+	return listen(b, "notice", "group_increase", l)
 }
 
 type GroupBanNoticeSubType string
@@ -345,8 +345,8 @@ type GroupBanNotice struct {
 }
 
 // ListenGroupBanNotice 监听群禁言
-func (b *Bot) ListenGroupBanNotice(l func(notice *GroupBanNotice) bool) {
-	listen(b, "notice", "group_ban", l)
+func (b *Bot) ListenGroupBanNotice(l func(notice *GroupBanNotice) bool) *Listener {
+	return listen(b, "notice", "group_ban", l)
 }
 
 // FriendAddNotice 好友添加
@@ -359,8 +359,8 @@ type FriendAddNotice struct {
 }
 
 // ListenFriendAddNotice 监听好友添加
-func (b *Bot) ListenFriendAddNotice(l func(notice *FriendAddNotice) bool) {
-	listen(b, "notice", "friend_add", l)
+func (b *Bot) ListenFriendAddNotice(l func(notice *FriendAddNotice) bool) *Listener {
+	return listen(b, "notice", "friend_add", l)
 }
 
 // GroupRecallNotice 群消息撤回
@@ -376,8 +376,8 @@ type GroupRecallNotice struct {
 }
 
 // ListenGroupRecallNotice 监听群消息撤回
-func (b *Bot) ListenGroupRecallNotice(l func(notice *GroupRecallNotice) bool) {
-	listen(b, "notice", "group_recall", l)
+func (b *Bot) ListenGroupRecallNotice(l func(notice *GroupRecallNotice) bool) *Listener {
+	return listen(b, "notice", "group_recall", l)
 }
 
 // FriendRecallNotice 好友消息撤回
@@ -391,8 +391,8 @@ type FriendRecallNotice struct {
 }
 
 // ListenFriendRecallNotice 监听好友消息撤回
-func (b *Bot) ListenFriendRecallNotice(l func(notice *FriendRecallNotice) bool) {
-	listen(b, "notice", "friend_recall", l)
+func (b *Bot) ListenFriendRecallNotice(l func(notice *FriendRecallNotice) bool) *Listener {
+	return listen(b, "notice", "friend_recall", l)
 }
 
 type NotifyNoticeSubType string
@@ -426,6 +426,6 @@ type NotifyNotice struct {
 }
 
 // ListenNotifyNotice 监听其它通知
-func (b *Bot) ListenNotifyNotice(l func(notice *NotifyNotice) bool) {
-	listen(b, "notice", "notify", l)
+func (b *Bot) ListenNotifyNotice(l func(notice *NotifyNotice) bool) *Listener {
+	return listen(b, "notice", "notify", l)
 }
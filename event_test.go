@@ -0,0 +1,94 @@
+package onebot
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+type testEssenceNotice struct {
+	PostType   string `json:"post_type"`
+	NoticeType string `json:"notice_type"`
+	SubType    string `json:"sub_type"`
+	GroupId    int64  `json:"group_id"`
+	MessageId  int64  `json:"message_id"`
+}
+
+func TestListen(t *testing.T) {
+	RegisterEvent[testEssenceNotice]("notice", "test_essence")
+	defer delete(builder["notice"], "test_essence")
+	b := newBot(0, false, defaultWriteTimeout)
+	essence := make(chan *testEssenceNotice, 1)
+	Listen(b, func(notice *testEssenceNotice) bool {
+		essence <- notice
+		return true
+	})
+	private := make(chan *PrivateMessage, 1)
+	Listen(b, func(message *PrivateMessage) bool {
+		private <- message
+		return true
+	})
+	b.onMessage(slog.Default(), []byte(`{"post_type":"notice","notice_type":"test_essence","sub_type":"add","group_id":1000,"message_id":123}`))
+	b.onMessage(slog.Default(), []byte(`{"post_type":"message","message_type":"private","user_id":2000,"message":[]}`))
+	select {
+	case notice := <-essence:
+		if notice.GroupId != 1000 || notice.MessageId != 123 || notice.SubType != "add" {
+			t.Fatal(notice)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("event not received")
+	}
+	select {
+	case message := <-private:
+		if message.UserId != 2000 {
+			t.Fatal(message)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("event not received")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expect panic")
+		}
+	}()
+	Listen(b, func(*struct{ A int }) bool { return true })
+}
+
+func TestListenRaw(t *testing.T) {
+	b := newBot(0, false, defaultWriteTimeout)
+	var raws, unknowns []string
+	done := make(chan struct{}, 1)
+	b.ListenRaw(func(raw gjson.Result) bool {
+		raws = append(raws, raw.Get("notice_type").String())
+		return raw.Get("notice_type").String() != "friend_recall"
+	})
+	b.ListenUnknown(func(raw gjson.Result) bool {
+		unknowns = append(unknowns, raw.Get("notice_type").String())
+		return true
+	})
+	b.ListenFriendRecallNotice(func(*FriendRecallNotice) bool {
+		t.Error("should be stopped by raw listener")
+		return true
+	})
+	b.ListenFriendAddNotice(func(*FriendAddNotice) bool {
+		done <- struct{}{}
+		return true
+	})
+	b.onMessage(slog.Default(), []byte(`{"post_type":"notice","notice_type":"group_card","group_id":1000}`))
+	b.onMessage(slog.Default(), []byte(`{"post_type":"notice","notice_type":"friend_recall","user_id":2000}`))
+	b.onMessage(slog.Default(), []byte(`{"post_type":"notice","notice_type":"friend_add","user_id":2000}`))
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("event not received")
+	}
+	if len(raws) != 3 || raws[0] != "group_card" || raws[1] != "friend_recall" || raws[2] != "friend_add" {
+		t.Fatal(raws)
+	}
+	if len(unknowns) != 1 || unknowns[0] != "group_card" {
+		t.Fatal(unknowns)
+	}
+}
@@ -0,0 +1,274 @@
+package onebot
+
+// builderV12 OneBot 12 的事件，以 type 和 detail_type 区分
+var builderV12 = make(map[string]map[string]func() any)
+
+func init() {
+	builderV12["message"] = map[string]func() any{
+		"private": func() any { return &PrivateMessageV12{} },
+		"group":   func() any { return &GroupMessageV12{} },
+		"channel": func() any { return &ChannelMessageV12{} },
+	}
+	builderV12["notice"] = map[string]func() any{
+		"friend_increase":        func() any { return &FriendIncreaseNoticeV12{} },
+		"friend_decrease":        func() any { return &FriendDecreaseNoticeV12{} },
+		"private_message_delete": func() any { return &PrivateMessageDeleteNoticeV12{} },
+		"group_member_increase":  func() any { return &GroupMemberIncreaseNoticeV12{} },
+		"group_member_decrease":  func() any { return &GroupMemberDecreaseNoticeV12{} },
+		"group_message_delete":   func() any { return &GroupMessageDeleteNoticeV12{} },
+	}
+	builderV12["meta"] = map[string]func() any{
+		"connect":       func() any { return &ConnectMetaEventV12{} },
+		"heartbeat":     func() any { return &HeartbeatMetaEventV12{} },
+		"status_update": func() any { return &StatusUpdateMetaEventV12{} },
+	}
+}
+
+// BotSelf OneBot 12 中表示机器人自身
+type BotSelf struct {
+	Platform string `json:"platform"` // 平台名称，例如"qq"
+	UserId   string `json:"user_id"`  // 机器人用户 ID
+}
+
+// PrivateMessageV12 私聊消息（OneBot 12）
+type PrivateMessageV12 struct {
+	Id         string          `json:"id"`          // 事件唯一标识符
+	Time       float64         `json:"time"`        // 事件发生的时间戳，单位秒
+	Type       string          `json:"type"`        // "message"
+	DetailType string          `json:"detail_type"` // "private"
+	SubType    string          `json:"sub_type"`    // 消息子类型，标准中为空字符串
+	Self       BotSelf         `json:"self"`        // 机器人自身标识
+	MessageId  string          `json:"message_id"`  // 消息 ID
+	Message    MessageChainV12 `json:"message"`     // 消息内容
+	AltMessage string          `json:"alt_message"` // 消息内容的替代表示
+	UserId     string          `json:"user_id"`     // 用户 ID
+}
+
+// Reply 回复，返回消息ID
+func (m *PrivateMessageV12) Reply(b *Bot, reply MessageChainV12) (string, error) {
+	return b.SendMessageV12(&MessageTargetV12{DetailType: "private", UserId: m.UserId}, reply)
+}
+
+// ListenPrivateMessageV12 监听私聊消息（OneBot 12）
+func (b *Bot) ListenPrivateMessageV12(l func(message *PrivateMessageV12) bool) *Listener {
+	return listen(b, "message", "private", l)
+}
+
+// GroupMessageV12 群消息（OneBot 12）
+type GroupMessageV12 struct {
+	Id         string          `json:"id"`          // 事件唯一标识符
+	Time       float64         `json:"time"`        // 事件发生的时间戳，单位秒
+	Type       string          `json:"type"`        // "message"
+	DetailType string          `json:"detail_type"` // "group"
+	SubType    string          `json:"sub_type"`    // 消息子类型，标准中为空字符串
+	Self       BotSelf         `json:"self"`        // 机器人自身标识
+	MessageId  string          `json:"message_id"`  // 消息 ID
+	Message    MessageChainV12 `json:"message"`     // 消息内容
+	AltMessage string          `json:"alt_message"` // 消息内容的替代表示
+	GroupId    string          `json:"group_id"`    // 群 ID
+	UserId     string          `json:"user_id"`     // 用户 ID
+}
+
+// Reply 回复，返回消息ID
+func (m *GroupMessageV12) Reply(b *Bot, reply MessageChainV12) (string, error) {
+	return b.SendMessageV12(&MessageTargetV12{DetailType: "group", GroupId: m.GroupId}, reply)
+}
+
+// ListenGroupMessageV12 监听群消息（OneBot 12）
+func (b *Bot) ListenGroupMessageV12(l func(message *GroupMessageV12) bool) *Listener {
+	return listen(b, "message", "group", l)
+}
+
+// ChannelMessageV12 频道消息（OneBot 12）
+type ChannelMessageV12 struct {
+	Id         string          `json:"id"`          // 事件唯一标识符
+	Time       float64         `json:"time"`        // 事件发生的时间戳，单位秒
+	Type       string          `json:"type"`        // "message"
+	DetailType string          `json:"detail_type"` // "channel"
+	SubType    string          `json:"sub_type"`    // 消息子类型，标准中为空字符串
+	Self       BotSelf         `json:"self"`        // 机器人自身标识
+	MessageId  string          `json:"message_id"`  // 消息 ID
+	Message    MessageChainV12 `json:"message"`     // 消息内容
+	AltMessage string          `json:"alt_message"` // 消息内容的替代表示
+	GuildId    string          `json:"guild_id"`    // 群组 ID
+	ChannelId  string          `json:"channel_id"`  // 频道 ID
+	UserId     string          `json:"user_id"`     // 用户 ID
+}
+
+// Reply 回复，返回消息ID
+func (m *ChannelMessageV12) Reply(b *Bot, reply MessageChainV12) (string, error) {
+	return b.SendMessageV12(&MessageTargetV12{DetailType: "channel", GuildId: m.GuildId, ChannelId: m.ChannelId}, reply)
+}
+
+// ListenChannelMessageV12 监听频道消息（OneBot 12）
+func (b *Bot) ListenChannelMessageV12(l func(message *ChannelMessageV12) bool) *Listener {
+	return listen(b, "message", "channel", l)
+}
+
+// FriendIncreaseNoticeV12 好友增加（OneBot 12）
+type FriendIncreaseNoticeV12 struct {
+	Id         string  `json:"id"`          // 事件唯一标识符
+	Time       float64 `json:"time"`        // 事件发生的时间戳，单位秒
+	Type       string  `json:"type"`        // "notice"
+	DetailType string  `json:"detail_type"` // "friend_increase"
+	SubType    string  `json:"sub_type"`    // 事件子类型，标准中为空字符串
+	Self       BotSelf `json:"self"`        // 机器人自身标识
+	UserId     string  `json:"user_id"`     // 用户 ID
+}
+
+// ListenFriendIncreaseNoticeV12 监听好友增加（OneBot 12）
+func (b *Bot) ListenFriendIncreaseNoticeV12(l func(notice *FriendIncreaseNoticeV12) bool) *Listener {
+	return listen(b, "notice", "friend_increase", l)
+}
+
+// FriendDecreaseNoticeV12 好友减少（OneBot 12）
+type FriendDecreaseNoticeV12 struct {
+	Id         string  `json:"id"`          // 事件唯一标识符
+	Time       float64 `json:"time"`        // 事件发生的时间戳，单位秒
+	Type       string  `json:"type"`        // "notice"
+	DetailType string  `json:"detail_type"` // "friend_decrease"
+	SubType    string  `json:"sub_type"`    // 事件子类型，标准中为空字符串
+	Self       BotSelf `json:"self"`        // 机器人自身标识
+	UserId     string  `json:"user_id"`     // 用户 ID
+}
+
+// ListenFriendDecreaseNoticeV12 监听好友减少（OneBot 12）
+func (b *Bot) ListenFriendDecreaseNoticeV12(l func(notice *FriendDecreaseNoticeV12) bool) *Listener {
+	return listen(b, "notice", "friend_decrease", l)
+}
+
+// PrivateMessageDeleteNoticeV12 私聊消息删除（OneBot 12）
+type PrivateMessageDeleteNoticeV12 struct {
+	Id         string  `json:"id"`          // 事件唯一标识符
+	Time       float64 `json:"time"`        // 事件发生的时间戳，单位秒
+	Type       string  `json:"type"`        // "notice"
+	DetailType string  `json:"detail_type"` // "private_message_delete"
+	SubType    string  `json:"sub_type"`    // 事件子类型，标准中为空字符串
+	Self       BotSelf `json:"self"`        // 机器人自身标识
+	MessageId  string  `json:"message_id"`  // 消息 ID
+	UserId     string  `json:"user_id"`     // 消息发送者 ID
+}
+
+// ListenPrivateMessageDeleteNoticeV12 监听私聊消息删除（OneBot 12）
+func (b *Bot) ListenPrivateMessageDeleteNoticeV12(l func(notice *PrivateMessageDeleteNoticeV12) bool) *Listener {
+	return listen(b, "notice", "private_message_delete", l)
+}
+
+// GroupMemberIncreaseNoticeV12 群成员增加（OneBot 12），SubType 为"join"表示成员主动加入，"invite"表示被邀请
+type GroupMemberIncreaseNoticeV12 struct {
+	Id         string  `json:"id"`          // 事件唯一标识符
+	Time       float64 `json:"time"`        // 事件发生的时间戳，单位秒
+	Type       string  `json:"type"`        // "notice"
+	DetailType string  `json:"detail_type"` // "group_member_increase"
+	SubType    string  `json:"sub_type"`    // 事件子类型
+	Self       BotSelf `json:"self"`        // 机器人自身标识
+	GroupId    string  `json:"group_id"`    // 群 ID
+	UserId     string  `json:"user_id"`     // 用户 ID
+	OperatorId string  `json:"operator_id"` // 操作者 ID
+}
+
+// ListenGroupMemberIncreaseNoticeV12 监听群成员增加（OneBot 12）
+func (b *Bot) ListenGroupMemberIncreaseNoticeV12(l func(notice *GroupMemberIncreaseNoticeV12) bool) *Listener {
+	return listen(b, "notice", "group_member_increase", l)
+}
+
+// GroupMemberDecreaseNoticeV12 群成员减少（OneBot 12），SubType 为"leave"表示主动退出，"kick"表示被踢出
+type GroupMemberDecreaseNoticeV12 struct {
+	Id         string  `json:"id"`          // 事件唯一标识符
+	Time       float64 `json:"time"`        // 事件发生的时间戳，单位秒
+	Type       string  `json:"type"`        // "notice"
+	DetailType string  `json:"detail_type"` // "group_member_decrease"
+	SubType    string  `json:"sub_type"`    // 事件子类型
+	Self       BotSelf `json:"self"`        // 机器人自身标识
+	GroupId    string  `json:"group_id"`    // 群 ID
+	UserId     string  `json:"user_id"`     // 用户 ID
+	OperatorId string  `json:"operator_id"` // 操作者 ID
+}
+
+// ListenGroupMemberDecreaseNoticeV12 监听群成员减少（OneBot 12）
+func (b *Bot) ListenGroupMemberDecreaseNoticeV12(l func(notice *GroupMemberDecreaseNoticeV12) bool) *Listener {
+	return listen(b, "notice", "group_member_decrease", l)
+}
+
+// GroupMessageDeleteNoticeV12 群消息删除（OneBot 12），SubType 为"recall"表示发送者撤回，"delete"表示管理员删除
+type GroupMessageDeleteNoticeV12 struct {
+	Id         string  `json:"id"`          // 事件唯一标识符
+	Time       float64 `json:"time"`        // 事件发生的时间戳，单位秒
+	Type       string  `json:"type"`        // "notice"
+	DetailType string  `json:"detail_type"` // "group_message_delete"
+	SubType    string  `json:"sub_type"`    // 事件子类型
+	Self       BotSelf `json:"self"`        // 机器人自身标识
+	GroupId    string  `json:"group_id"`    // 群 ID
+	MessageId  string  `json:"message_id"`  // 消息 ID
+	UserId     string  `json:"user_id"`     // 消息发送者 ID
+	OperatorId string  `json:"operator_id"` // 操作者 ID
+}
+
+// ListenGroupMessageDeleteNoticeV12 监听群消息删除（OneBot 12）
+func (b *Bot) ListenGroupMessageDeleteNoticeV12(l func(notice *GroupMessageDeleteNoticeV12) bool) *Listener {
+	return listen(b, "notice", "group_message_delete", l)
+}
+
+// VersionInfoV12 OneBot 12 实现的版本信息
+type VersionInfoV12 struct {
+	Impl          string `json:"impl"`           // 实现名称
+	Version       string `json:"version"`        // 实现版本
+	OnebotVersion string `json:"onebot_version"` // OneBot 标准版本，例如"12"
+}
+
+// ConnectMetaEventV12 连接成功后推送的元事件（OneBot 12）
+type ConnectMetaEventV12 struct {
+	Id         string         `json:"id"`          // 事件唯一标识符
+	Time       float64        `json:"time"`        // 事件发生的时间戳，单位秒
+	Type       string         `json:"type"`        // "meta"
+	DetailType string         `json:"detail_type"` // "connect"
+	SubType    string         `json:"sub_type"`    // 事件子类型，标准中为空字符串
+	Version    VersionInfoV12 `json:"version"`     // 版本信息
+}
+
+// ListenConnectMetaEventV12 监听连接元事件（OneBot 12）
+func (b *Bot) ListenConnectMetaEventV12(l func(event *ConnectMetaEventV12) bool) *Listener {
+	return listen(b, "meta", "connect", l)
+}
+
+// HeartbeatMetaEventV12 心跳（OneBot 12）
+type HeartbeatMetaEventV12 struct {
+	Id         string  `json:"id"`          // 事件唯一标识符
+	Time       float64 `json:"time"`        // 事件发生的时间戳，单位秒
+	Type       string  `json:"type"`        // "meta"
+	DetailType string  `json:"detail_type"` // "heartbeat"
+	SubType    string  `json:"sub_type"`    // 事件子类型，标准中为空字符串
+	Interval   int64   `json:"interval"`    // 到下次心跳的间隔，单位毫秒
+}
+
+// ListenHeartbeatMetaEventV12 监听心跳（OneBot 12）
+func (b *Bot) ListenHeartbeatMetaEventV12(l func(event *HeartbeatMetaEventV12) bool) *Listener {
+	return listen(b, "meta", "heartbeat", l)
+}
+
+// BotStatusV12 OneBot 12 中单个机器人的状态
+type BotStatusV12 struct {
+	Self   BotSelf `json:"self"`   // 机器人自身标识
+	Online bool    `json:"online"` // 是否在线
+}
+
+// StatusV12 OneBot 12 实现的运行状态
+type StatusV12 struct {
+	Good bool            `json:"good"` // 是否各项状态都符合预期
+	Bots []*BotStatusV12 `json:"bots"` // 当前 OneBot 连接上的所有机器人账号的状态
+}
+
+// StatusUpdateMetaEventV12 状态更新（OneBot 12）
+type StatusUpdateMetaEventV12 struct {
+	Id         string    `json:"id"`          // 事件唯一标识符
+	Time       float64   `json:"time"`        // 事件发生的时间戳，单位秒
+	Type       string    `json:"type"`        // "meta"
+	DetailType string    `json:"detail_type"` // "status_update"
+	SubType    string    `json:"sub_type"`    // 事件子类型，标准中为空字符串
+	Status     StatusV12 `json:"status"`      // 状态信息
+}
+
+// ListenStatusUpdateMetaEventV12 监听状态更新（OneBot 12）
+func (b *Bot) ListenStatusUpdateMetaEventV12(l func(event *StatusUpdateMetaEventV12) bool) *Listener {
+	return listen(b, "meta", "status_update", l)
+}
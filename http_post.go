@@ -0,0 +1,166 @@
+package onebot
+
+import (
+	"crypto/hmac"
+	"crypto/sha1" // nolint:gosec
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// HttpEventHandler 通过HTTP POST接收OneBot上报的事件，并分发给 Bot 上注册的监听者。
+//
+// 在监听者中调用 GroupMessage.Reply 等快速操作方法时，如果还没有超过 Timeout ，
+// 快速操作会直接作为HTTP响应返回给OneBot，否则会退回到调用 .handle_quick_operation 接口。
+type HttpEventHandler struct {
+	Bot     *Bot
+	Secret  string        // 如果不为空，则用它校验 X-Signature 头的 HMAC-SHA1 签名
+	Timeout time.Duration // 等待监听者给出快速操作的最长时间，为0则不等待
+	MaxBody int64         // 请求体的最大字节数，超过时返回413，小于等于0时为 defaultMaxBody
+}
+
+// defaultMaxBody 默认的请求体最大字节数
+const defaultMaxBody = 8 << 20
+
+// NewHttpEventHandler 新建一个HTTP POST事件接收器，secret需要与OneBot配置的一致
+func NewHttpEventHandler(b *Bot, secret string) *HttpEventHandler {
+	return &HttpEventHandler{Bot: b, Secret: secret, Timeout: 3 * time.Second}
+}
+
+func (h *HttpEventHandler) checkSignature(r *http.Request, body []byte) int {
+	if len(h.Secret) == 0 {
+		return 0
+	}
+	sig, ok := strings.CutPrefix(r.Header.Get("X-Signature"), "sha1=")
+	if !ok {
+		return http.StatusUnauthorized
+	}
+	expected, err := hex.DecodeString(sig)
+	if err != nil {
+		return http.StatusForbidden
+	}
+	mac := hmac.New(sha1.New, []byte(h.Secret))
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return http.StatusForbidden
+	}
+	return 0
+}
+
+func (h *HttpEventHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	maxBody := h.MaxBody
+	if maxBody <= 0 {
+		maxBody = defaultMaxBody
+	}
+	// 校验签名之前请求体是不可信的，需要限制大小
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBody))
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		} else {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		return
+	}
+	if code := h.checkSignature(r, body); code != 0 {
+		w.WriteHeader(code)
+		return
+	}
+	log := slog.With("remote", r.RemoteAddr)
+	log.Debug("recv", "msg", string(body))
+	if !gjson.ValidBytes(body) {
+		log.Error("invalid json message")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	b := h.Bot
+	e := b.decodeEvent(log, gjson.ParseBytes(body), body)
+	if e == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if h.Timeout <= 0 || e.Value == nil {
+		b.startEvent(log, e, func() { b.handleEvent(log, e) })
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	slot := &quickOperationSlot{}
+	b.quickOperationSlots.Store(e.Value, slot)
+	done := make(chan struct{})
+	b.startEvent(log, e, func() {
+		defer close(done)
+		b.handleEvent(log, e)
+	})
+	timer := time.NewTimer(h.Timeout)
+	select {
+	case <-done:
+		timer.Stop()
+	case <-timer.C:
+		log.Warn("wait for quick operation timeout")
+	}
+	b.quickOperationSlots.Delete(e.Value)
+	operation := slot.take()
+	if len(operation) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	buf, err := json.Marshal(operation)
+	if err != nil {
+		log.Error("json marshal failed", "error", err)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	log.Debug("send", "msg", string(buf))
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(buf)
+}
+
+// quickOperationSlot 收集监听者对同一个事件发起的快速操作，多次操作会合并成一个
+type quickOperationSlot struct {
+	lock      sync.Mutex
+	closed    bool
+	operation map[string]json.RawMessage
+}
+
+// put 放入快速操作，如果HTTP响应已经返回，则返回false
+func (s *quickOperationSlot) put(operation any) bool {
+	buf, err := json.Marshal(operation)
+	if err != nil {
+		slog.Error("json marshal failed", "error", err)
+		return false
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return false
+	}
+	if s.operation == nil {
+		s.operation = make(map[string]json.RawMessage)
+	}
+	if err = json.Unmarshal(buf, &s.operation); err != nil {
+		slog.Error("json unmarshal failed", "error", err)
+		return false
+	}
+	return true
+}
+
+// take 取出所有快速操作，此后不能再放入
+func (s *quickOperationSlot) take() map[string]json.RawMessage {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.closed = true
+	return s.operation
+}
@@ -0,0 +1,51 @@
+package onebot
+
+import (
+	"crypto/hmac"
+	"crypto/sha1" // nolint:gosec
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHttpEventHandler(t *testing.T) {
+	b := newBot(1, false, defaultWriteTimeout)
+	b.ListenGroupMessage(func(message *GroupMessage) bool {
+		_ = message.Reply(b, message.Message, true)
+		return true
+	})
+	h := NewHttpEventHandler(b, "secret")
+	body := `{"post_type":"message","message_type":"group","group_id":1000,"user_id":2000,"message":[{"type":"text","data":{"text":"hi"}}]}`
+	post := func(signature string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		if len(signature) > 0 {
+			r.Header.Set("X-Signature", signature)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := post(""); w.Code != http.StatusUnauthorized {
+		t.Fatal(w.Code)
+	}
+	if w := post("sha1=0000"); w.Code != http.StatusForbidden {
+		t.Fatal(w.Code)
+	}
+	mac := hmac.New(sha1.New, []byte("secret"))
+	mac.Write([]byte(body))
+	w := post("sha1=" + hex.EncodeToString(mac.Sum(nil)))
+	if w.Code != http.StatusOK {
+		t.Fatal(w.Code)
+	}
+	if w.Body.String() != `{"at_sender":true,"reply":[{"type":"text","data":{"text":"hi"}}]}` {
+		t.Fatal(w.Body.String())
+	}
+
+	h.MaxBody = 16
+	if w := post("sha1=" + hex.EncodeToString(mac.Sum(nil))); w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatal(w.Code)
+	}
+}
@@ -0,0 +1,121 @@
+package onebot
+
+import (
+	"cmp"
+	"slices"
+	"sync/atomic"
+)
+
+// Listener 通过 ListenXxx 注册的监听者，可以通过 Remove 移除
+//
+//	listener := b.ListenGroupMessage(func(message *onebot.GroupMessage) bool {
+//		...
+//	})
+//	defer listener.Remove()
+type Listener struct {
+	b        *botCore
+	seq      uint64 // 注册序号，优先级相同时先注册的先调用
+	priority int    // 优先级，越大越先调用，只在持有 handlerLock 时访问
+	once     bool   // 是否只处理一次事件
+	removed  atomic.Bool
+}
+
+// listenEntry 监听者的一个处理函数，同一个 Listener 可能监听多种事件
+type listenEntry struct {
+	listener *Listener
+	f        listenHandler
+}
+
+func (b *Bot) newListener(once bool) *Listener {
+	b.handlerLock.Lock()
+	defer b.handlerLock.Unlock()
+	b.listenerSeq++
+	return &Listener{b: b.botCore, seq: b.listenerSeq, once: once}
+}
+
+// add 把处理函数加入对应事件的监听者列表中
+func (l *Listener) add(key, subKey string, f listenHandler) {
+	b := l.b
+	b.handlerLock.Lock()
+	defer b.handlerLock.Unlock()
+	if b.handler[key] == nil {
+		b.handler[key] = make(map[string][]*listenEntry)
+	}
+	b.handler[key][subKey] = insertListenEntry(b.handler[key][subKey], l, f)
+}
+
+// acquire 在调用处理函数之前调用，返回false表示已经移除，不应该再处理事件
+func (l *Listener) acquire() bool {
+	if !l.once {
+		return !l.removed.Load()
+	}
+	if !l.removed.CompareAndSwap(false, true) {
+		return false
+	}
+	l.remove()
+	return true
+}
+
+// Remove 移除监听者，之后不会再收到任何事件。可以重复调用
+func (l *Listener) Remove() {
+	l.removed.Store(true)
+	l.remove()
+}
+
+func (l *Listener) remove() {
+	b := l.b
+	b.handlerLock.Lock()
+	defer b.handlerLock.Unlock()
+	for key, m := range b.handler {
+		for subKey, entries := range m {
+			if entries = removeListenEntry(entries, l); len(entries) == 0 {
+				delete(m, subKey)
+			} else {
+				m[subKey] = entries
+			}
+		}
+		if len(m) == 0 {
+			delete(b.handler, key)
+		}
+	}
+	b.rawHandler = removeListenEntry(b.rawHandler, l)
+	b.unknownHandler = removeListenEntry(b.unknownHandler, l)
+}
+
+// SetPriority 设置优先级，优先级越大越先处理事件，默认为0，优先级相同时先注册的先处理。返回自身以便链式调用
+//
+//	b.ListenGroupMessage(f).SetPriority(100)
+func (l *Listener) SetPriority(priority int) *Listener {
+	b := l.b
+	b.handlerLock.Lock()
+	defer b.handlerLock.Unlock()
+	l.priority = priority
+	for _, m := range b.handler {
+		for subKey, entries := range m {
+			m[subKey] = sortListenEntries(entries)
+		}
+	}
+	b.rawHandler = sortListenEntries(b.rawHandler)
+	b.unknownHandler = sortListenEntries(b.unknownHandler)
+	return l
+}
+
+// insertListenEntry 返回加入了新的处理函数的列表，不修改原来的列表
+func insertListenEntry(entries []*listenEntry, l *Listener, f listenHandler) []*listenEntry {
+	return sortListenEntries(append(slices.Clip(entries), &listenEntry{listener: l, f: f}))
+}
+
+// removeListenEntry 返回移除了监听者的列表，不修改原来的列表
+func removeListenEntry(entries []*listenEntry, l *Listener) []*listenEntry {
+	if !slices.ContainsFunc(entries, func(e *listenEntry) bool { return e.listener == l }) {
+		return entries
+	}
+	return slices.DeleteFunc(slices.Clone(entries), func(e *listenEntry) bool { return e.listener == l })
+}
+
+// sortListenEntries 返回按照优先级排序的列表，不修改原来的列表
+func sortListenEntries(entries []*listenEntry) []*listenEntry {
+	return slices.SortedStableFunc(slices.Values(entries), func(a, b *listenEntry) int {
+		return cmp.Or(cmp.Compare(b.listener.priority, a.listener.priority), cmp.Compare(a.listener.seq, b.listener.seq))
+	})
+}
@@ -0,0 +1,55 @@
+package onebot
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestListener(t *testing.T) {
+	b := newBot(0, false, defaultWriteTimeout)
+	var calls []string
+	done := make(chan struct{}, 1)
+	b.ListenFriendAddNotice(func(*FriendAddNotice) bool {
+		calls = append(calls, "normal")
+		return true
+	})
+	removed := b.ListenFriendAddNotice(func(*FriendAddNotice) bool {
+		calls = append(calls, "removed")
+		return true
+	})
+	b.ListenFriendAddNotice(func(*FriendAddNotice) bool {
+		calls = append(calls, "high")
+		return true
+	}).SetPriority(10)
+	ListenOnce(b, func(*FriendAddNotice) bool {
+		calls = append(calls, "once")
+		return true
+	}).SetPriority(5)
+	b.ListenFriendAddNotice(func(*FriendAddNotice) bool {
+		done <- struct{}{}
+		return true
+	}).SetPriority(-1)
+	removed.Remove()
+	removed.Remove()
+	for range 2 {
+		b.onMessage(slog.Default(), []byte(`{"post_type":"notice","notice_type":"friend_add","user_id":2000}`))
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("event not received")
+		}
+	}
+	expected := []string{"high", "once", "normal", "high", "normal"}
+	if len(calls) != len(expected) {
+		t.Fatal(calls)
+	}
+	for i := range calls {
+		if calls[i] != expected[i] {
+			t.Fatal(calls)
+		}
+	}
+	if n := len(b.handler["notice"]["friend_add"]); n != 3 {
+		t.Fatal(n)
+	}
+}
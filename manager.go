@@ -0,0 +1,197 @@
+package onebot
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// BotManager 管理多个账号的 Bot ，以QQ号区分
+type BotManager struct {
+	lock   sync.RWMutex
+	bots   map[int64]*Bot
+	setups []func(b *Bot)
+}
+
+// NewBotManager 新建一个 BotManager
+func NewBotManager() *BotManager {
+	return &BotManager{bots: make(map[int64]*Bot)}
+}
+
+// OnBot 注册一个初始化函数，对已经加入和以后加入的每个 Bot 都会调用一次，一般用来注册监听：
+//
+//	m.OnBot(func(b *onebot.Bot) {
+//		b.ListenGroupMessage(func(message *onebot.GroupMessage) bool {
+//			_, _ = b.SendGroupMessage(message.GroupId, message.Message)
+//			return true
+//		})
+//	})
+func (m *BotManager) OnBot(f func(b *Bot)) {
+	m.lock.Lock()
+	m.setups = append(m.setups, f)
+	bots := make([]*Bot, 0, len(m.bots))
+	for _, b := range m.bots {
+		bots = append(bots, b)
+	}
+	m.lock.Unlock()
+	for _, b := range bots {
+		f(b)
+	}
+}
+
+// Connect 同 ConnectWithOptions ，连接成功后加入管理。
+//
+// 如果没有通过 WithQQ 指定QQ号，则通过 Bot.GetLoginInfo 获取，
+// 如果OneBot实现不支持，则等待生命周期事件中的 self_id ，直到 ctx 结束。
+// 此时 WithOnConnected 设置的回调在获取到QQ号之后才会调用
+func (m *BotManager) Connect(ctx context.Context, addr string, opts ...Option) (*Bot, error) {
+	selfId := make(chan int64, 1)
+	var lifecycle *Listener
+	var ready atomic.Bool
+	var onConnected func(b *Bot)
+	opts = append(opts, func(o *options) {
+		if o.qq != 0 {
+			return
+		}
+		onConnected = o.onConnected
+		if onConnected != nil {
+			o.onConnected = func(b *Bot) {
+				if ready.Load() {
+					onConnected(b)
+				}
+			}
+		}
+		o.beforeRead = func(b *Bot) {
+			// 此时还没有开始读取消息，所以不会错过连接成功时的生命周期事件
+			lifecycle = b.ListenLifecycleMetaEvent(func(event *LifecycleMetaEvent) bool {
+				select {
+				case selfId <- event.SelfId:
+				default:
+				}
+				return true
+			})
+		}
+	})
+	b, err := ConnectWithOptions(ctx, addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if lifecycle != nil {
+		// 获取到QQ号之前， Bot 还没有交给其它代码，所以可以直接修改 b.QQ
+		qq, err := resolveSelfId(ctx, b, selfId)
+		lifecycle.Remove()
+		if err != nil {
+			_ = b.Close()
+			return nil, err
+		}
+		b.QQ = qq
+		ready.Store(true)
+		if onConnected != nil {
+			b.runHook(onConnected)
+		}
+	}
+	m.add(b)
+	return b, nil
+}
+
+// resolveSelfId 通过 Bot.GetLoginInfo 获取QQ号，失败时等待生命周期事件中的 self_id
+func resolveSelfId(ctx context.Context, b *Bot, selfId <-chan int64) (int64, error) {
+	info, err := b.WithContext(ctx).GetLoginInfo()
+	if err == nil {
+		return info.UserId, nil
+	}
+	select {
+	case qq := <-selfId:
+		return qq, nil
+	case <-ctx.Done():
+		return 0, errors.Join(err, ctx.Err())
+	}
+}
+
+// ListenReverse 同 ListenReverse ，连接上来的账号会自动加入管理，opts.OnConnect 会在加入之后调用
+func (m *BotManager) ListenReverse(addr string, opts ReverseOptions) (*ReverseServer, error) {
+	onConnect := opts.OnConnect
+	opts.OnConnect = func(b *Bot) {
+		m.add(b)
+		if onConnect != nil {
+			onConnect(b)
+		}
+	}
+	return ListenReverse(addr, opts)
+}
+
+// Add 把已经连接的 Bot 加入管理，如果 b.QQ 为0则通过 Bot.GetLoginInfo 获取
+func (m *BotManager) Add(b *Bot) error {
+	if b.QQ == 0 {
+		info, err := b.GetLoginInfo()
+		if err != nil {
+			return err
+		}
+		b.QQ = info.UserId
+	}
+	m.add(b)
+	return nil
+}
+
+// add 加入管理，如果已经有相同QQ号的 Bot ，则关闭旧的
+func (m *BotManager) add(b *Bot) {
+	m.lock.Lock()
+	old := m.bots[b.QQ]
+	m.bots[b.QQ] = b
+	setups := m.setups
+	m.lock.Unlock()
+	if old != nil && old.botCore != b.botCore {
+		_ = old.Close()
+	}
+	for _, f := range setups {
+		f(b)
+	}
+}
+
+// Get 根据QQ号获取 Bot ，不存在则返回nil
+func (m *BotManager) Get(selfId int64) *Bot {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.bots[selfId]
+}
+
+// Remove 移除并关闭指定QQ号的 Bot
+func (m *BotManager) Remove(selfId int64) error {
+	m.lock.Lock()
+	b := m.bots[selfId]
+	delete(m.bots, selfId)
+	m.lock.Unlock()
+	if b != nil {
+		return b.Close()
+	}
+	return nil
+}
+
+// Each 遍历所有 Bot ，f返回false时停止遍历
+func (m *BotManager) Each(f func(b *Bot) bool) {
+	m.lock.RLock()
+	bots := make([]*Bot, 0, len(m.bots))
+	for _, b := range m.bots {
+		bots = append(bots, b)
+	}
+	m.lock.RUnlock()
+	for _, b := range bots {
+		if !f(b) {
+			return
+		}
+	}
+}
+
+// CloseAll 关闭并移除所有 Bot
+func (m *BotManager) CloseAll() error {
+	m.lock.Lock()
+	bots := m.bots
+	m.bots = make(map[int64]*Bot)
+	m.lock.Unlock()
+	var err error
+	for _, b := range bots {
+		err = errors.Join(err, b.Close())
+	}
+	return err
+}
@@ -0,0 +1,95 @@
+package onebot
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/tidwall/gjson"
+)
+
+func TestBotManager(t *testing.T) {
+	newServer := func(selfId int64) string {
+		return newTestServer(t, func(c *websocket.Conn) {
+			for {
+				_, message, err := c.ReadMessage()
+				if err != nil {
+					return
+				}
+				resp := fmt.Sprintf(`{"status":"ok","retcode":0,"data":{"user_id":%d,"nickname":"bot"},"echo":%d}`, selfId, gjson.GetBytes(message, "echo").Int())
+				if err = c.WriteMessage(websocket.TextMessage, []byte(resp)); err != nil {
+					return
+				}
+			}
+		})
+	}
+	m := NewBotManager()
+	defer func() { _ = m.CloseAll() }()
+	var setups []int64
+	m.OnBot(func(b *Bot) { setups = append(setups, b.QQ) })
+	for _, selfId := range []int64{10001, 10002} {
+		b, err := m.Connect(context.Background(), newServer(selfId))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b.QQ != selfId || m.Get(selfId) != b {
+			t.Fatal(b.QQ)
+		}
+	}
+	if len(setups) != 2 || setups[0] != 10001 || setups[1] != 10002 {
+		t.Fatal(setups)
+	}
+	count := 0
+	m.Each(func(*Bot) bool {
+		count++
+		return true
+	})
+	if count != 2 {
+		t.Fatal(count)
+	}
+	if err := m.Remove(10001); err != nil {
+		t.Fatal(err)
+	}
+	if m.Get(10001) != nil {
+		t.Fatal("bot not removed")
+	}
+}
+
+func TestBotManagerLifecycle(t *testing.T) {
+	// 不支持 get_login_info 的OneBot实现，只能从生命周期事件中获取QQ号
+	addr := newTestServer(t, func(c *websocket.Conn) {
+		if err := c.WriteMessage(websocket.TextMessage, []byte(`{"post_type":"meta_event","meta_event_type":"lifecycle","sub_type":"connect","self_id":10003}`)); err != nil {
+			return
+		}
+		for {
+			_, message, err := c.ReadMessage()
+			if err != nil {
+				return
+			}
+			resp := fmt.Sprintf(`{"status":"failed","retcode":1404,"data":null,"echo":%d}`, gjson.GetBytes(message, "echo").Int())
+			if err = c.WriteMessage(websocket.TextMessage, []byte(resp)); err != nil {
+				return
+			}
+		}
+	})
+	m := NewBotManager()
+	defer func() { _ = m.CloseAll() }()
+	connected := make(chan int64, 1)
+	b, err := m.Connect(context.Background(), addr, WithOnConnected(func(b *Bot) { connected <- b.QQ }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.QQ != 10003 || m.Get(10003) != b {
+		t.Fatal(b.QQ)
+	}
+	if qq := <-connected; qq != 10003 {
+		t.Fatal(qq)
+	}
+	b.handlerLock.RLock()
+	n := len(b.handler["meta_event"]["lifecycle"])
+	b.handlerLock.RUnlock()
+	if n != 0 {
+		t.Fatal("lifecycle listener not removed")
+	}
+}
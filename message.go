@@ -3,9 +3,7 @@ package onebot
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
 	"log/slog"
-	"strings"
 
 	"github.com/tidwall/gjson"
 )
@@ -17,12 +15,21 @@ type messageSegment struct {
 
 type MessageChain []SingleMessage
 
-func (c *MessageChain) MarshalJSON() ([]byte, error) {
-	segments := make([]messageSegment, 0, len(*c))
-	for _, m := range *c {
+// MarshalJSON 使用值接收者，这样放在 map[string]any 等不可寻址的位置时也能正确序列化
+func (c MessageChain) MarshalJSON() ([]byte, error) {
+	return marshalMessageChain(c)
+}
+
+func marshalMessageChain(c []SingleMessage) ([]byte, error) {
+	segments := make([]messageSegment, 0, len(c))
+	for _, m := range c {
+		var data any = m
+		if raw, ok := m.(*RawSegment); ok {
+			data = raw.data()
+		}
 		segments = append(segments, messageSegment{
 			Type: m.GetMessageType(),
-			Data: m,
+			Data: data,
 		})
 	}
 	return json.Marshal(segments)
@@ -33,10 +40,19 @@ func (c *MessageChain) UnmarshalJSON(data []byte) error {
 		return errors.New("invalid json data")
 	}
 	result := gjson.ParseBytes(data)
+	if result.Type == gjson.String {
+		// OneBot 的 message_format 配置为 string 时，消息是CQ码格式的字符串
+		chain, err := ParseCQCode(result.String())
+		if err != nil {
+			return err
+		}
+		*c = chain
+		return nil
+	}
 	if !result.IsArray() {
 		return errors.New("result is not array")
 	}
-	*c = parseMessageChain(result.Array())
+	*c = parseMessageChain(singleMessageBuilder, result.Array())
 	return nil
 }
 
@@ -67,7 +83,7 @@ func (m *Face) GetMessageType() string {
 }
 
 func (m *Face) String() string {
-	return fmt.Sprintf("[CQ:face,id=%s]", m.Id)
+	return cqCode(m)
 }
 
 // Image 图片
@@ -98,7 +114,7 @@ func (m *Image) GetMessageType() string {
 }
 
 func (m *Image) String() string {
-	return fmt.Sprintf("[CQ:image,file=%s]", m.File)
+	return cqCode(m)
 }
 
 // Record 语音
@@ -129,7 +145,7 @@ func (m *Record) GetMessageType() string {
 }
 
 func (m *Record) String() string {
-	return fmt.Sprintf("[CQ:record,file=%s]", m.File)
+	return cqCode(m)
 }
 
 // Video 短视频
@@ -157,7 +173,7 @@ func (m *Video) GetMessageType() string {
 }
 
 func (m *Video) String() string {
-	return fmt.Sprintf("[CQ:video,file=%s]", m.File)
+	return cqCode(m)
 }
 
 // At @某人
@@ -170,7 +186,7 @@ func (m *At) GetMessageType() string {
 }
 
 func (m *At) String() string {
-	return fmt.Sprintf("[CQ:at,qq=%s]", m.QQ)
+	return cqCode(m)
 }
 
 // RPS 猜拳魔法表情
@@ -182,7 +198,7 @@ func (m *RPS) GetMessageType() string {
 }
 
 func (m *RPS) String() string {
-	return "[CQ:rps]"
+	return cqCode(m)
 }
 
 // Dice 掷骰子魔法表情
@@ -194,7 +210,7 @@ func (m *Dice) GetMessageType() string {
 }
 
 func (m *Dice) String() string {
-	return "[CQ:dice]"
+	return cqCode(m)
 }
 
 // Shake 窗口抖动
@@ -206,7 +222,7 @@ func (m *Shake) GetMessageType() string {
 }
 
 func (m *Shake) String() string {
-	return "[CQ:shake]"
+	return cqCode(m)
 }
 
 // Poke 戳一戳，字段含义参考文档
@@ -223,7 +239,7 @@ func (m *Poke) GetMessageType() string {
 }
 
 func (m *Poke) String() string {
-	return fmt.Sprintf("[CQ:poke,type=%s,id=%s]", m.Type, m.Id)
+	return cqCode(m)
 }
 
 // Anonymous 匿名发消息
@@ -236,7 +252,7 @@ func (m *Anonymous) GetMessageType() string {
 }
 
 func (m *Anonymous) String() string {
-	return "[CQ:anonymous]"
+	return cqCode(m)
 }
 
 // Share 链接分享
@@ -252,7 +268,7 @@ func (m *Share) GetMessageType() string {
 }
 
 func (m *Share) String() string {
-	return fmt.Sprintf("[CQ:share,url=%s,title=%s]", m.Url, m.Title)
+	return cqCode(m)
 }
 
 type ContactType string
@@ -273,7 +289,7 @@ func (m *Contact) GetMessageType() string {
 }
 
 func (m *Contact) String() string {
-	return fmt.Sprintf("[CQ:contact,type=%s,id=%s]", m.Type, m.Id)
+	return cqCode(m)
 }
 
 // Location 位置
@@ -289,7 +305,7 @@ func (m *Location) GetMessageType() string {
 }
 
 func (m *Location) String() string {
-	return fmt.Sprintf("[CQ:location,lat=%s,lon=%s]", m.Lat, m.Lon)
+	return cqCode(m)
 }
 
 // Music 音乐分享
@@ -314,10 +330,7 @@ func (m *Music) GetMessageType() string {
 }
 
 func (m *Music) String() string {
-	if m.Type == "custom" {
-		return fmt.Sprintf("[CQ:music,type=custom,url=%s,audio=%s,title=%s]", m.Url, m.Audio, m.Title)
-	}
-	return fmt.Sprintf("[CQ:music,type=%s,id=%s]", m.Type, m.Id)
+	return cqCode(m)
 }
 
 // Reply 回复
@@ -330,7 +343,7 @@ func (m *Reply) GetMessageType() string {
 }
 
 func (m *Reply) String() string {
-	return fmt.Sprintf("[CQ:reply,id=%s]", m.Id)
+	return cqCode(m)
 }
 
 // Forward 合并转发
@@ -343,7 +356,7 @@ func (m *Forward) GetMessageType() string {
 }
 
 func (m *Forward) String() string {
-	return fmt.Sprintf("[CQ:forward,id=%s]", m.Id)
+	return cqCode(m)
 }
 
 // Node 合并转发节点
@@ -362,14 +375,7 @@ func (m *Node) GetMessageType() string {
 }
 
 func (m *Node) String() string {
-	if len(m.Id) == 0 {
-		var sb strings.Builder
-		for _, m := range m.Content {
-			_, _ = fmt.Fprint(&sb, m)
-		}
-		return fmt.Sprintf("[CQ:node,user_id=%s,nickname=%s,content=%s]", m.UserId, m.Nickname, sb.String())
-	}
-	return fmt.Sprintf("[CQ:node,id=%s]", m.Id)
+	return cqCode(m)
 }
 
 type Xml struct {
@@ -381,7 +387,7 @@ func (m *Xml) GetMessageType() string {
 }
 
 func (m *Xml) String() string {
-	return fmt.Sprintf("[CQ:xml,data=%s]", m.Data)
+	return cqCode(m)
 }
 
 type Json struct {
@@ -393,15 +399,47 @@ func (m *Json) GetMessageType() string {
 }
 
 func (m *Json) String() string {
-	return fmt.Sprintf("[CQ:json,data=%s]", m.Data)
+	return cqCode(m)
+}
+
+// RawSegment 未知类型的消息段，例如 NapCat 、 LLOneBot 扩展的 markdown 、 mface 等，
+// 原样保存 data 的内容，发送时也会原样发出
+type RawSegment struct {
+	Type string          // 消息段类型
+	Data json.RawMessage // 消息段的 data 字段
+}
+
+func (m *RawSegment) GetMessageType() string {
+	return m.Type
+}
+
+func (m *RawSegment) String() string {
+	return cqCode(m)
+}
+
+func (m *RawSegment) data() json.RawMessage {
+	if len(m.Data) == 0 {
+		return json.RawMessage("{}")
+	}
+	return m.Data
 }
 
 var singleMessageBuilder = make(map[string]func() SingleMessage)
 
+// RegisterSegment 注册自定义的消息段类型，类型名为 f().GetMessageType() ，已经存在的类型会被覆盖。
+// 收到该类型的消息段时会用f创建对象，再把 data 字段用json反序列化进去。
+//
+// 没有注册的类型会被解析为 RawSegment 。需要在连接之前调用，例如在init函数中：
+//
+//	func init() {
+//		onebot.RegisterSegment(func() onebot.SingleMessage { return &Markdown{} })
+//	}
+func RegisterSegment(f func() SingleMessage) {
+	singleMessageBuilder[f().GetMessageType()] = f
+}
+
 func init() {
-	initMessageBuilder := func(f func() SingleMessage) {
-		singleMessageBuilder[f().GetMessageType()] = f
-	}
+	initMessageBuilder := RegisterSegment
 	initMessageBuilder(func() SingleMessage { return &Text{} })
 	initMessageBuilder(func() SingleMessage { return &Face{} })
 	initMessageBuilder(func() SingleMessage { return &Image{} })
@@ -424,7 +462,8 @@ func init() {
 	initMessageBuilder(func() SingleMessage { return &Json{} })
 }
 
-func parseMessageChain(results []gjson.Result) MessageChain {
+// parseMessageChain 用 builders 解析消息段，没有注册的类型解析为 RawSegment
+func parseMessageChain(builders map[string]func() SingleMessage, results []gjson.Result) MessageChain {
 	if len(results) == 0 {
 		return nil
 	}
@@ -435,7 +474,7 @@ func parseMessageChain(results []gjson.Result) MessageChain {
 			continue
 		}
 		singleMessageType := results[i].Get("type").String()
-		if builder, ok := singleMessageBuilder[singleMessageType]; ok {
+		if builder, ok := builders[singleMessageType]; ok {
 			m := builder()
 			if err := json.Unmarshal([]byte(results[i].Get("data").Raw), m); err == nil {
 				ret = append(ret, m)
@@ -443,7 +482,7 @@ func parseMessageChain(results []gjson.Result) MessageChain {
 				slog.Error("json unmarshal failed", "buf", results[i].Get("data").Raw, "error", err)
 			}
 		} else {
-			slog.Error("unknown single message type: " + results[i].String())
+			ret = append(ret, &RawSegment{Type: singleMessageType, Data: json.RawMessage(results[i].Get("data").Raw)})
 		}
 	}
 	return ret
@@ -2,6 +2,7 @@ package onebot
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 )
 
@@ -30,3 +31,186 @@ func TestMessage(t *testing.T) {
 		t.Fatal(privateMessage)
 	}
 }
+
+// TestMessageChainNotAddressable 消息链放在 map[string]any 、切片等不可寻址的位置时也要按消息段格式序列化
+func TestMessageChainNotAddressable(t *testing.T) {
+	chain := MessageChain{&Text{Text: "hi"}, &RawSegment{Type: "markdown", Data: json.RawMessage(`{"content":"# hi"}`)}}
+	const expected = `[{"type":"text","data":{"text":"hi"}},{"type":"markdown","data":{"content":"# hi"}}]`
+	for _, v := range []any{
+		chain,
+		map[string]any{"message": chain},
+		[]any{chain},
+		struct{ Message MessageChain }{chain},
+	} {
+		buf, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(buf), expected) {
+			t.Fatal(string(buf))
+		}
+	}
+}
+
+func TestParseCQCode(t *testing.T) {
+	chain, err := ParseCQCode("&#91;你好&#93;[CQ:face,id=178][CQ:share,url=https://a.com/?a=1&amp;b=2,title=A&#44;B]&amp;[CQ:record,file=1.amr,magic=1]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chain) != 5 {
+		t.Fatal(chain)
+	}
+	if text, ok := chain[0].(*Text); !ok || text.Text != "[你好]" {
+		t.Fatal(chain[0])
+	}
+	if face, ok := chain[1].(*Face); !ok || face.Id != "178" {
+		t.Fatal(chain[1])
+	}
+	if share, ok := chain[2].(*Share); !ok || share.Url != "https://a.com/?a=1&b=2" || share.Title != "A,B" {
+		t.Fatal(chain[2])
+	}
+	if text, ok := chain[3].(*Text); !ok || text.Text != "&" {
+		t.Fatal(chain[3])
+	}
+	if record, ok := chain[4].(*Record); !ok || record.File != "1.amr" || !record.Magic {
+		t.Fatal(chain[4])
+	}
+	if _, err = ParseCQCode("[CQ:face,id=178"); err == nil {
+		t.Fatal("expect error")
+	}
+
+	var privateMessage *PrivateMessage
+	err = json.Unmarshal([]byte(`{"user_id":1000,"message":"hello[CQ:at,qq=123]"}`), &privateMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(privateMessage.Message) != 2 {
+		t.Fatal(privateMessage.Message)
+	}
+	if at, ok := privateMessage.Message[1].(*At); !ok || at.QQ != "123" {
+		t.Fatal(privateMessage.Message[1])
+	}
+}
+
+func TestCQString(t *testing.T) {
+	chain := MessageChain{
+		&Text{Text: "[你好]&"},
+		&Share{Url: "https://a.com/?a=1&b=2", Title: "A,B", Content: "[内容]"},
+		&Image{File: "1.jpg", Type: "flash"},
+		&Record{File: "1.amr", Magic: true},
+	}
+	s := chain.CQString()
+	expected := "&#91;你好&#93;&amp;[CQ:share,url=https://a.com/?a=1&amp;b=2,title=A&#44;B,content=&#91;内容&#93;][CQ:image,file=1.jpg,type=flash][CQ:record,file=1.amr,magic=1]"
+	if s != expected {
+		t.Fatal(s)
+	}
+	parsed, err := ParseCQCode(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.CQString() != s {
+		t.Fatal(parsed.CQString())
+	}
+	if share, ok := parsed[1].(*Share); !ok || *share != *chain[1].(*Share) {
+		t.Fatal(parsed[1])
+	}
+	if s := (MessageChain{&Text{Text: "[a]"}}).CQString(); s != "&#91;a&#93;" {
+		t.Fatal(s)
+	}
+	// Text.String 返回原始文本，只在 CQString 中转义
+	if text := parsed[0].(*Text); text.String() != "[你好]&" {
+		t.Fatal(text.String())
+	}
+}
+
+type testMarkdown struct {
+	Content string `json:"content"`
+}
+
+func (m *testMarkdown) GetMessageType() string {
+	return "test_markdown"
+}
+
+func TestRawSegment(t *testing.T) {
+	const data = `[{"type":"mface","data":{"emoji_id":"1","key":"a,b","extra":{"x":[1,2]}}},{"type":"text","data":{"text":"hi"}}]`
+	var chain MessageChain
+	if err := json.Unmarshal([]byte(data), &chain); err != nil {
+		t.Fatal(err)
+	}
+	if raw, ok := chain[0].(*RawSegment); !ok || raw.Type != "mface" {
+		t.Fatal(chain[0])
+	}
+	buf, err := json.Marshal(&chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != data {
+		t.Fatal(string(buf))
+	}
+	if s := chain.CQString(); s != "[CQ:mface,emoji_id=1,key=a&#44;b]hi" {
+		t.Fatal(s)
+	}
+
+	RegisterSegment(func() SingleMessage { return &testMarkdown{} })
+	defer delete(singleMessageBuilder, "test_markdown")
+	if err = json.Unmarshal([]byte(`[{"type":"test_markdown","data":{"content":"# title"}}]`), &chain); err != nil {
+		t.Fatal(err)
+	}
+	if markdown, ok := chain[0].(*testMarkdown); !ok || markdown.Content != "# title" {
+		t.Fatal(chain[0])
+	}
+}
+
+func TestMessageChainV12(t *testing.T) {
+	// OneBot 11 下 OneBot 12 的消息段类型不生效，NapCat 的 file 消息段原样保留
+	const v11 = `[{"type":"file","data":{"file":"a.txt","file_id":"abc","file_size":"12","url":"http://x"}},{"type":"mention","data":{"user_id":"1"}}]`
+	var chain MessageChain
+	if err := json.Unmarshal([]byte(v11), &chain); err != nil {
+		t.Fatal(err)
+	}
+	if raw, ok := chain[0].(*RawSegment); !ok || raw.Type != "file" {
+		t.Fatal(chain[0])
+	}
+	if _, ok := chain[1].(*RawSegment); !ok {
+		t.Fatal(chain[1])
+	}
+	if buf, err := json.Marshal(chain); err != nil || string(buf) != v11 {
+		t.Fatal(string(buf), err)
+	}
+
+	const v12 = `[{"type":"mention","data":{"user_id":"1"}},{"type":"image","data":{"file_id":"img"}},` +
+		`{"type":"location","data":{"latitude":31.5,"longitude":121.25,"title":"t","content":"c"}},` +
+		`{"type":"reply","data":{"message_id":"6283","user_id":"2"}},{"type":"file","data":{"file_id":"abc"}},` +
+		`{"type":"face","data":{"id":"178"}}]`
+	var chainV12 MessageChainV12
+	if err := json.Unmarshal([]byte(v12), &chainV12); err != nil {
+		t.Fatal(err)
+	}
+	if len(chainV12) != 6 {
+		t.Fatal(chainV12)
+	}
+	if m, ok := chainV12[1].(*ImageV12); !ok || m.FileId != "img" {
+		t.Fatal(chainV12[1])
+	}
+	if m, ok := chainV12[2].(*LocationV12); !ok || m.Latitude != 31.5 || m.Longitude != 121.25 {
+		t.Fatal(chainV12[2])
+	}
+	if m, ok := chainV12[3].(*ReplyV12); !ok || m.MessageId != "6283" || m.UserId != "2" {
+		t.Fatal(chainV12[3])
+	}
+	if m, ok := chainV12[4].(*FileV12); !ok || m.FileId != "abc" {
+		t.Fatal(chainV12[4])
+	}
+	if _, ok := chainV12[5].(*RawSegment); !ok {
+		t.Fatal(chainV12[5]) // face 不是 OneBot 12 的标准消息段
+	}
+	if buf, err := json.Marshal(chainV12); err != nil || string(buf) != v12 {
+		t.Fatal(string(buf), err)
+	}
+
+	// OneBot 11 的消息段保持原来的格式
+	buf, err := json.Marshal(MessageChain{&Image{}, &Location{Lat: "31.5", Lon: "121.25"}, &Reply{Id: "1"}})
+	if err != nil || string(buf) != `[{"type":"image","data":{"file":""}},{"type":"location","data":{"lat":"31.5","lon":"121.25"}},{"type":"reply","data":{"id":"1"}}]` {
+		t.Fatal(string(buf), err)
+	}
+}
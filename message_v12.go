@@ -0,0 +1,167 @@
+package onebot
+
+import (
+	"errors"
+
+	"github.com/tidwall/gjson"
+)
+
+// MessageChainV12 OneBot 12 的消息。OneBot 12 的消息段与 OneBot 11 不同，
+// 例如图片为 ImageV12 ，提及用户为 Mention ，没有注册的类型会被解析为 RawSegment
+type MessageChainV12 []SingleMessage
+
+func (c MessageChainV12) MarshalJSON() ([]byte, error) {
+	return marshalMessageChain(c)
+}
+
+func (c *MessageChainV12) UnmarshalJSON(data []byte) error {
+	if !gjson.ValidBytes(data) {
+		return errors.New("invalid json data")
+	}
+	result := gjson.ParseBytes(data)
+	if !result.IsArray() {
+		return errors.New("result is not array")
+	}
+	*c = MessageChainV12(parseMessageChain(singleMessageBuilderV12, result.Array()))
+	return nil
+}
+
+// ImageV12 图片（OneBot 12）
+type ImageV12 struct {
+	FileId string `json:"file_id"` // 图片文件 ID，通过 Bot.UploadFile 上传后获得
+}
+
+func (m *ImageV12) GetMessageType() string {
+	return "image"
+}
+
+func (m *ImageV12) String() string {
+	return cqCode(m)
+}
+
+// VideoV12 视频（OneBot 12）
+type VideoV12 struct {
+	FileId string `json:"file_id"` // 视频文件 ID
+}
+
+func (m *VideoV12) GetMessageType() string {
+	return "video"
+}
+
+func (m *VideoV12) String() string {
+	return cqCode(m)
+}
+
+// LocationV12 位置（OneBot 12）
+type LocationV12 struct {
+	Latitude  float64 `json:"latitude"`  // 纬度
+	Longitude float64 `json:"longitude"` // 经度
+	Title     string  `json:"title"`     // 标题
+	Content   string  `json:"content"`   // 地址内容
+}
+
+func (m *LocationV12) GetMessageType() string {
+	return "location"
+}
+
+func (m *LocationV12) String() string {
+	return cqCode(m)
+}
+
+// ReplyV12 回复（OneBot 12）
+type ReplyV12 struct {
+	MessageId string `json:"message_id"`        // 回复时引用的消息 ID
+	UserId    string `json:"user_id,omitempty"` // 被引用消息的发送者 ID，发送时可不填
+}
+
+func (m *ReplyV12) GetMessageType() string {
+	return "reply"
+}
+
+func (m *ReplyV12) String() string {
+	return cqCode(m)
+}
+
+// Mention 提及用户（OneBot 12）
+type Mention struct {
+	UserId string `json:"user_id"` // 用户 ID
+}
+
+func (m *Mention) GetMessageType() string {
+	return "mention"
+}
+
+func (m *Mention) String() string {
+	return cqCode(m)
+}
+
+// MentionAll 提及所有人（OneBot 12）
+type MentionAll struct {
+}
+
+func (m *MentionAll) GetMessageType() string {
+	return "mention_all"
+}
+
+func (m *MentionAll) String() string {
+	return cqCode(m)
+}
+
+// Voice 语音（OneBot 12），一般表示用户录音发送
+type Voice struct {
+	FileId string `json:"file_id"` // 语音文件 ID
+}
+
+func (m *Voice) GetMessageType() string {
+	return "voice"
+}
+
+func (m *Voice) String() string {
+	return cqCode(m)
+}
+
+// Audio 音频（OneBot 12），一般表示音乐文件
+type Audio struct {
+	FileId string `json:"file_id"` // 音频文件 ID
+}
+
+func (m *Audio) GetMessageType() string {
+	return "audio"
+}
+
+func (m *Audio) String() string {
+	return cqCode(m)
+}
+
+// FileV12 文件（OneBot 12）
+type FileV12 struct {
+	FileId string `json:"file_id"` // 文件 ID
+}
+
+func (m *FileV12) GetMessageType() string {
+	return "file"
+}
+
+func (m *FileV12) String() string {
+	return cqCode(m)
+}
+
+var singleMessageBuilderV12 = make(map[string]func() SingleMessage)
+
+// RegisterSegmentV12 注册自定义的 OneBot 12 消息段类型，用法同 RegisterSegment ，只对 MessageChainV12 生效
+func RegisterSegmentV12(f func() SingleMessage) {
+	singleMessageBuilderV12[f().GetMessageType()] = f
+}
+
+func init() {
+	RegisterSegmentV12(func() SingleMessage { return &Text{} })
+	RegisterSegmentV12(func() SingleMessage { return &Mention{} })
+	RegisterSegmentV12(func() SingleMessage { return &MentionAll{} })
+	RegisterSegmentV12(func() SingleMessage { return &ImageV12{} })
+	RegisterSegmentV12(func() SingleMessage { return &Voice{} })
+	RegisterSegmentV12(func() SingleMessage { return &Audio{} })
+	RegisterSegmentV12(func() SingleMessage { return &VideoV12{} })
+	RegisterSegmentV12(func() SingleMessage { return &FileV12{} })
+	RegisterSegmentV12(func() SingleMessage { return &LocationV12{} })
+	RegisterSegmentV12(func() SingleMessage { return &ReplyV12{} })
+}
@@ -0,0 +1,46 @@
+package onebot
+
+import (
+	"slices"
+
+	"github.com/tidwall/gjson"
+)
+
+// Event 交给中间件的事件
+type Event struct {
+	Bot *Bot
+
+	// OneBot 11 中为 post_type 和 <post_type>_type 字段，例如"message"和"group"，
+	// OneBot 12 中为 type 和 detail_type 字段
+	PostType string
+	SubType  string
+
+	Raw   gjson.Result // 原始的事件
+	Value any          // 解析后的事件，例如 *GroupMessage ，没有注册类型时为nil
+}
+
+// EventHandler 处理事件的函数
+type EventHandler func(e *Event)
+
+// Middleware 中间件，包装处理事件的函数，不调用next则事件不会交给后续的中间件和监听者
+//
+//	b.Use(func(next onebot.EventHandler) onebot.EventHandler {
+//		return func(e *onebot.Event) {
+//			if m, ok := e.Value.(*onebot.GroupMessage); ok && blacklist[m.UserId] {
+//				return
+//			}
+//			next(e)
+//		}
+//	})
+type Middleware func(next EventHandler) EventHandler
+
+// Use 添加中间件，所有事件都会按照添加的顺序经过中间件，然后交给 ListenRaw 和其它的监听者。
+//
+// 中间件与监听者在同一个协程中调用。只有正在被 WaitNext 等待的事件，中间件会在新的协程中调用，
+// 此时即使 Connect 的 concurrentEvent 为false，中间件也会与其它事件的中间件和监听者并发执行，有状态的中间件请自行加锁。
+// 中间件放行之后才会交给 WaitNext ，被截获的事件不会再交给普通的监听者
+func (b *Bot) Use(middlewares ...Middleware) {
+	b.handlerLock.Lock()
+	defer b.handlerLock.Unlock()
+	b.middlewares = append(slices.Clip(b.middlewares), middlewares...)
+}
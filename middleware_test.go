@@ -0,0 +1,51 @@
+package onebot
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestMiddleware(t *testing.T) {
+	b := newBot(0, false, defaultWriteTimeout)
+	var calls []string
+	done := make(chan struct{}, 1)
+	b.Use(func(next EventHandler) EventHandler {
+		return func(e *Event) {
+			calls = append(calls, "first:"+e.PostType+"/"+e.SubType)
+			next(e)
+			done <- struct{}{}
+		}
+	}, func(next EventHandler) EventHandler {
+		return func(e *Event) {
+			if m, ok := e.Value.(*GroupMessage); ok && m.UserId == 3000 {
+				calls = append(calls, "blocked")
+				return
+			}
+			next(e)
+		}
+	})
+	b.ListenGroupMessage(func(message *GroupMessage) bool {
+		calls = append(calls, "listener")
+		return true
+	})
+	b.onMessage(slog.Default(), []byte(`{"post_type":"message","message_type":"group","group_id":1000,"user_id":2000,"message":[]}`))
+	b.onMessage(slog.Default(), []byte(`{"post_type":"message","message_type":"group","group_id":1000,"user_id":3000,"message":[]}`))
+	b.onMessage(slog.Default(), []byte(`{"post_type":"notice","notice_type":"group_card","group_id":1000}`))
+	for range 3 {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("event not received")
+		}
+	}
+	expected := []string{"first:message/group", "listener", "first:message/group", "blocked", "first:notice/group_card"}
+	if len(calls) != len(expected) {
+		t.Fatal(calls)
+	}
+	for i := range calls {
+		if calls[i] != expected[i] {
+			t.Fatal(calls)
+		}
+	}
+}
@@ -10,8 +10,8 @@ import (
 	"github.com/tidwall/gjson"
 	"golang.org/x/time/rate"
 	"log/slog"
-	"net/http"
 	"runtime/debug"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -31,28 +31,86 @@ const (
 	WsChannelAll = ""
 )
 
+// ProtocolVersion OneBot标准的版本
+type ProtocolVersion int
+
+const (
+	ProtocolV11 ProtocolVersion = 11 // OneBot 11，默认
+	ProtocolV12 ProtocolVersion = 12 // OneBot 12
+)
+
 // Connect 连接onebot
 //
 // concurrentEvent 参数如果是true，表示采用并发方式处理事件和消息，由调用者自行解决并发问题。
-// 如果是false表示用单线程处理事件和消息，调用者无需关心并发问题。
+// 如果是false表示用单线程处理事件和消息，调用者无需关心监听者之间的并发问题。
+//
+// 注意：使用 Bot.Use 添加的中间件例外。正在被 WaitNext 或 Session 等待的事件，因为处理事件的协程可能正被等待它的监听者占用，
+// 所以会在新的协程中经过中间件，与其它事件的中间件和监听者并发执行。中间件中如果有状态（例如去重用的map、计数器），请自行加锁。
+//
+// 如果需要更多的连接选项，请使用 ConnectWithOptions
 func Connect(host string, port int, channel WsChannel, accessToken string, qq int64, concurrentEvent bool) (*Bot, error) {
-	addr := fmt.Sprintf("ws://%s:%d/%s", host, port, channel)
-	log := slog.With("addr", addr)
+	return ConnectWithOptions(context.Background(), fmt.Sprintf("ws://%s:%d/%s", host, port, channel),
+		WithAccessToken(accessToken), WithQQ(qq), WithConcurrentEvent(concurrentEvent))
+}
+
+// ConnectWithOptions 连接onebot，addr 是完整的地址，例如 ws://127.0.0.1:8080/ 或 wss://example.com/onebot/api
+//
+// 如果通过 WithEventAddr 设置了事件连接的地址，则 addr 只用于调用API，两条连接各自断线重连。
+//
+// ctx 只用于控制首次连接，连接成功后断线重连不受 ctx 影响
+func ConnectWithOptions(ctx context.Context, addr string, opts ...Option) (*Bot, error) {
+	o := newOptions(opts)
+	log := o.logger.With("addr", addr)
+	dial := o.dialFunc(addr, log)
 	log.Info("Dialing")
-	var header http.Header
-	if len(accessToken) > 0 {
-		header = http.Header{"Authorization": []string{"Bearer " + accessToken}}
-	}
-	c, resp, err := websocket.DefaultDialer.Dial(addr, header) // nolint:bodyclose
+	c, err := dial(ctx)
 	if err != nil {
-		log.Error("Connect failed")
 		return nil, err
 	}
-	if resp != nil {
-		_ = resp.Body.Close()
+	var eventLog *slog.Logger
+	var eventDial func(ctx context.Context) (*websocket.Conn, error)
+	var ec *websocket.Conn
+	if len(o.eventAddr) > 0 {
+		eventLog = o.logger.With("addr", o.eventAddr)
+		eventDial = o.dialFunc(o.eventAddr, eventLog)
+		eventLog.Info("Dialing")
+		if ec, err = eventDial(ctx); err != nil {
+			_ = c.Close()
+			return nil, err
+		}
+	}
+	b := newBot(o.qq, o.concurrentEvent || o.shard != nil, o.writeTimeout)
+	if o.shard != nil {
+		b.shards = newShards(*o.shard, b.done)
+	}
+	b.waitReconnect = o.waitReconnect
+	b.recorder.Store(o.recorder)
+	b.version = o.version
+	b.api.set(c)
+	if ec != nil {
+		b.event = newConn(o.writeTimeout)
+		b.event.set(ec)
+	}
+	if o.beforeRead != nil {
+		o.beforeRead(b)
 	}
-	log.Info("Connected successfully")
-	b := &Bot{QQ: qq, c: c, handler: make(map[string]map[string][]listenHandler)}
+	if o.onConnected != nil {
+		b.runHook(o.onConnected)
+	}
+	go b.keepAlive(b.api, dial, o, log)
+	if b.event != nil {
+		go b.keepAlive(b.event, eventDial, o, eventLog)
+	}
+	return b, nil
+}
+
+func newBot(qq int64, concurrentEvent bool, writeTimeout time.Duration) *Bot {
+	b := &Bot{QQ: qq, botCore: &botCore{
+		handler: make(map[string]map[string][]*listenEntry),
+		api:     newConn(writeTimeout),
+		done:    make(chan struct{}),
+	}}
+	b.defaultTimeout.Store(int64(defaultTimeout))
 	if !concurrentEvent {
 		b.eventChan = goutil.NewBlockingQueue[func()]()
 		go func() {
@@ -61,106 +119,273 @@ func Connect(host string, port int, channel WsChannel, accessToken string, qq in
 			}
 		}()
 	}
-	go func() {
-		for !b.closed.Load() {
-			if b.c == nil {
-				time.Sleep(3 * time.Second)
-				log.Info("trying to reconnect")
-				c, resp, err = websocket.DefaultDialer.Dial(addr, header) // nolint:bodyclose
-				if err != nil {
-					log.Error("Connect failed")
-					continue
-				}
-				if resp != nil {
-					_ = resp.Body.Close()
-				}
-				log.Info("Connected successfully")
-				b.c = c
-			}
-			for {
-				t, message, err := b.c.ReadMessage()
-				if err != nil {
-					log.Error("read error", "error", err)
-					b.c = nil
-					break
-				}
-				if t != websocket.TextMessage {
-					continue
-				}
-				log.Debug("recv", "msg", string(message))
-				if !gjson.ValidBytes(message) {
-					log.Error("invalid json message")
-					continue
-				}
-				msg := gjson.ParseBytes(message)
-				echo := msg.Get("echo")
-				if echo.Exists() {
-					e := echo.Int()
-					retCode := msg.Get("retcode").Int()
-					if ch, ok := b.syncIdMap.LoadAndDelete(e); ok {
-						ch0 := ch.(chan gjson.Result)
-						if retCode != 0 {
-							log.Error("request failed", "retcode", retCode, "msg", msg.Get("message"))
-						} else {
-							ch0 <- msg.Get("data")
-						}
-						close(ch0)
-					}
-					continue
-				}
-				postType := msg.Get("post_type").String()
-				func() {
-					b.handlerLock.RLock()
-					defer b.handlerLock.RUnlock()
-					h, ok := b.handler[postType]
-					if !ok {
-						return
-					}
-					subType := msg.Get(postType + "_type").String()
-					h2, ok := h[subType]
-					if !ok {
-						return
-					}
-					if bd := builder[postType][subType]; bd == nil {
-						log.Error("cannot find message builder: " + postType)
-					} else {
-						m := bd()
-						err = json.Unmarshal(message, m)
-						if err != nil {
-							log.Error("json unmarshal failed", "error", err)
-							return
-						}
-						fun := func() {
-							defer func() {
-								if r := recover(); r != nil {
-									log.Error("panic recovered", "error", r, "stack", string(debug.Stack()))
-								}
-							}()
-							for _, f := range h2 {
-								if !f(m) {
-									break
-								}
-							}
-						}
-						b.Run(fun)
-					}
-				}()
-			}
+	return b
+}
+
+// onMessage 处理从连接中收到的一条消息，可能是API的响应，也可能是事件
+func (b *Bot) onMessage(log *slog.Logger, message []byte) {
+	log.Debug("recv", "msg", string(message))
+	if !gjson.ValidBytes(message) {
+		log.Error("invalid json message")
+		return
+	}
+	b.record(FrameIn, message)
+	msg := gjson.ParseBytes(message)
+	echo := msg.Get("echo")
+	if echo.Exists() {
+		if ch, ok := b.syncIdMap.LoadAndDelete(echo.Int()); ok {
+			ch0 := ch.(chan gjson.Result)
+			ch0 <- msg
+			close(ch0)
 		}
+		return
+	}
+	if b.holdEvent(log, msg, message) {
+		return
+	}
+	b.onEvent(log, msg, message)
+}
+
+// onEvent 解析并处理一个事件
+func (b *Bot) onEvent(log *slog.Logger, msg gjson.Result, message []byte) {
+	if e := b.decodeEvent(log, msg, message); e != nil {
+		b.startEvent(log, e, func() { b.handleEvent(log, e) })
+	}
+}
+
+// startEvent 开始处理事件。正在被 WaitNext 等待的事件在新的协程中处理，
+// 因为等待它的监听者可能正占用着处理事件的协程，其它事件同 runEvent
+func (b *Bot) startEvent(log *slog.Logger, e *incomingEvent, f func()) {
+	if e.waited {
+		go f()
+	} else {
+		b.runEvent(log, e, f)
+	}
+}
+
+// eventHold 连接钩子执行期间暂存收到的事件，钩子返回之后再按顺序处理，这样钩子中注册的监听者不会错过事件
+type eventHold struct {
+	lock    sync.Mutex
+	count   int // 正在执行的钩子数量
+	pending []heldEvent
+}
+
+type heldEvent struct {
+	log     *slog.Logger
+	msg     gjson.Result
+	message []byte
+}
+
+// holdEvent 如果有正在执行的钩子，则暂存事件并返回true
+func (b *Bot) holdEvent(log *slog.Logger, msg gjson.Result, message []byte) bool {
+	h := &b.eventHold
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if h.count == 0 {
+		return false
+	}
+	h.pending = append(h.pending, heldEvent{log, msg, message})
+	return true
+}
+
+// holdEvents 开始暂存事件，需要调用 releaseEvents 结束
+func (b *Bot) holdEvents() {
+	h := &b.eventHold
+	h.lock.Lock()
+	h.count++
+	h.lock.Unlock()
+}
+
+// releaseEvents 结束一次 holdEvents ，如果没有其它正在执行的钩子，则处理暂存的事件
+func (b *Bot) releaseEvents() {
+	h := &b.eventHold
+	for {
+		h.lock.Lock()
+		if h.count > 1 || len(h.pending) == 0 {
+			h.count--
+			h.lock.Unlock()
+			return
+		}
+		pending := h.pending
+		h.pending = nil
+		h.lock.Unlock()
+		// 处理期间新收到的事件继续暂存，保证顺序
+		for _, e := range pending {
+			b.onEvent(e.log, e.msg, e.message)
+		}
+	}
+}
+
+// runHook 在新的协程中调用连接钩子。调用时已经在读取消息，所以钩子中可以调用API，
+// 钩子返回之前收到的事件会在返回之后再处理
+func (b *Bot) runHook(f func(b *Bot)) {
+	b.holdEvents()
+	go func() {
+		defer b.releaseEvents()
+		f(b)
 	}()
-	return b, nil
 }
 
+// incomingEvent 一个需要处理的事件
+type incomingEvent struct {
+	Event
+	rawHandlers []*listenEntry // ListenRaw 的监听者，参数为 Raw
+	handlers    []*listenEntry // Value 对应的监听者，参数为 Value ，没有注册类型时为 ListenUnknown 的监听者，参数为 Raw
+	middlewares []Middleware
+	waited      bool // 是否有 WaitNext 等正在等待这个事件
+}
+
+// decodeEvent 解析事件，并返回对应的监听者。如果没有监听者或者解析失败，则返回nil
+func (b *Bot) decodeEvent(log *slog.Logger, msg gjson.Result, message []byte) *incomingEvent {
+	e := &incomingEvent{Event: Event{Bot: b, Raw: msg}}
+	builders := builder
+	if b.version == ProtocolV12 {
+		e.PostType, e.SubType = msg.Get("type").String(), msg.Get("detail_type").String()
+		builders = builderV12
+	} else {
+		e.PostType = msg.Get("post_type").String()
+		e.SubType = msg.Get(e.PostType + "_type").String()
+	}
+	b.handlerLock.RLock()
+	e.rawHandlers = b.rawHandler
+	bd := builders[e.PostType][e.SubType]
+	if bd == nil {
+		e.handlers = b.unknownHandler
+	} else {
+		e.handlers = b.handler[e.PostType][e.SubType]
+	}
+	waiters := b.waiters
+	e.middlewares = b.middlewares
+	b.handlerLock.RUnlock()
+	if bd == nil && len(e.handlers) == 0 {
+		log.Debug("cannot find message builder", "post_type", e.PostType, "sub_type", e.SubType)
+	}
+	if bd != nil && (len(e.handlers) > 0 || len(waiters) > 0 || len(e.middlewares) > 0) {
+		m := bd()
+		if err := json.Unmarshal(message, m); err != nil {
+			log.Error("json unmarshal failed", "error", err)
+			e.handlers = nil
+		} else {
+			e.Value = m
+		}
+	}
+	e.waited = e.Value != nil && hasWaiter(waiters, e.Value)
+	if len(e.rawHandlers) == 0 && len(e.handlers) == 0 && len(e.middlewares) == 0 && !e.waited {
+		return nil
+	}
+	return e
+}
+
+// handleEvent 依次经过所有的中间件后，先交给正在等待的 WaitNext ，没有被截获则调用 dispatchEvent
+func (b *Bot) handleEvent(log *slog.Logger, e *incomingEvent) {
+	defer recoverEvent(log)
+	h := func(*Event) {
+		if e.Value != nil && b.intercept(e.Value) {
+			// 被 WaitNext 等截获的事件不再交给普通的监听者
+			e.handlers = nil
+		}
+		if !e.waited {
+			b.dispatchEvent(e)
+		} else if len(e.rawHandlers) > 0 || len(e.handlers) > 0 {
+			// 中间件是在新的协程中调用的，监听者仍然按照原来的方式处理
+			b.runEvent(log, e, func() {
+				defer recoverEvent(log)
+				b.dispatchEvent(e)
+			})
+		}
+	}
+	for _, m := range slices.Backward(e.middlewares) {
+		h = m(h)
+	}
+	h(&e.Event)
+}
+
+func recoverEvent(log *slog.Logger) {
+	if r := recover(); r != nil {
+		log.Error("panic recovered", "error", r, "stack", string(debug.Stack()))
+	}
+}
+
+// dispatchEvent 先依次调用 ListenRaw 的监听者，再依次调用事件对应的监听者，直到某个监听者返回false
+func (b *Bot) dispatchEvent(e *incomingEvent) {
+	for _, h := range e.rawHandlers {
+		if !h.f(e.Raw) {
+			return
+		}
+	}
+	var m any = e.Raw
+	if e.Value != nil {
+		m = e.Value
+	}
+	for _, h := range e.handlers {
+		if !h.f(m) {
+			break
+		}
+	}
+}
+
+// defaultTimeout 默认的API请求超时时间
+const defaultTimeout = 5 * time.Second
+
 type Bot struct {
-	QQ          int64
-	c           *websocket.Conn
-	echo        atomic.Int64
-	handlerLock sync.RWMutex
-	handler     map[string]map[string][]listenHandler
-	syncIdMap   sync.Map
-	eventChan   *goutil.BlockingQueue[func()]
-	limiter     atomic.Pointer[limiter]
-	closed      atomic.Bool
+	QQ int64
+	*botCore
+
+	// 以下字段只对通过 WithContext 、 WithTimeout 得到的 Bot 生效
+	ctx     context.Context
+	timeout time.Duration
+}
+
+// botCore 是 Bot 的实际状态，通过 WithContext 、 WithTimeout 得到的 Bot 与原 Bot 共用同一个 botCore
+type botCore struct {
+	api                *conn // 用于调用API的连接，没有单独的事件连接时也用于接收事件
+	event              *conn // 单独的事件连接，可能为nil
+	waitReconnect      bool  // 断线时调用API是否等待重连
+	version            ProtocolVersion
+	done               chan struct{}
+	closeOnce          sync.Once
+	echo               atomic.Int64
+	handlerLock        sync.RWMutex
+	handler            map[string]map[string][]*listenEntry // 按照优先级排序，修改时整体替换，不会修改已有的切片
+	rawHandler         []*listenEntry                       // ListenRaw 的监听者
+	unknownHandler     []*listenEntry                       // ListenUnknown 的监听者
+	listenerSeq        uint64                               // 监听者的注册序号
+	waiters            []*waiter                            // 正在等待下一条消息的 WaitNextMessage 等，修改时整体替换
+	middlewares        []Middleware                         // 通过 Use 添加的中间件，修改时整体替换
+	syncIdMap          sync.Map
+	eventHold          eventHold
+	unsupportedActions sync.Map // 已知OneBot实现不支持的扩展API
+	eventChan          *goutil.BlockingQueue[func()]
+	shards             *shards // 分片处理事件，为nil表示不分片
+	limiter            atomic.Pointer[limiter]
+	transport          atomic.Pointer[Transport]
+	recorder           atomic.Pointer[Recorder]
+	closed             atomic.Bool
+	defaultTimeout     atomic.Int64
+
+	quickOperationSlots sync.Map // 通过HTTP POST收到的事件，快速操作通过HTTP响应返回
+}
+
+// WithContext 返回一个使用 ctx 调用API的 Bot ，ctx 被取消或超过截止时间时，正在等待的请求会立即返回。
+// 返回的 Bot 与原 Bot 共用连接、监听者和限流器等所有状态
+//
+//	msgId, err := b.WithContext(ctx).SendGroupMessage(groupId, message)
+func (b *Bot) WithContext(ctx context.Context) *Bot {
+	b2 := *b
+	b2.ctx = ctx
+	return &b2
+}
+
+// WithTimeout 返回一个使用指定超时时间调用API的 Bot ，不影响原 Bot 。timeout为0表示使用默认超时时间，小于0表示不超时
+func (b *Bot) WithTimeout(timeout time.Duration) *Bot {
+	b2 := *b
+	b2.timeout = timeout
+	return &b2
+}
+
+// SetTimeout 设置默认的API请求超时时间，默认为5秒。timeout小于等于0表示不超时
+func (b *Bot) SetTimeout(timeout time.Duration) {
+	b.defaultTimeout.Store(int64(timeout))
 }
 
 type limiter struct {
@@ -168,9 +393,9 @@ type limiter struct {
 	limiter     *rate.Limiter
 }
 
-func (l *limiter) check() bool {
+func (l *limiter) check(ctx context.Context) bool {
 	if l.limiterType == "wait" {
-		if err := l.limiter.Wait(context.Background()); err != nil {
+		if err := l.limiter.Wait(ctx); err != nil {
 			slog.Error("rate limiter wait error", "error", err)
 			return false
 		}
@@ -181,12 +406,37 @@ func (l *limiter) check() bool {
 }
 
 func (b *Bot) Close() error {
-	b.closed.Store(true)
-	c := b.c
-	if c != nil {
-		return c.Close()
+	var err error
+	b.closeOnce.Do(func() {
+		b.closed.Store(true)
+		close(b.done)
+		if c := b.api.get(); c != nil {
+			err = c.Close()
+		}
+		b.api.state.Store(int32(StateClosed))
+		if b.event != nil {
+			if c := b.event.get(); c != nil {
+				err = errors.Join(err, c.Close())
+			}
+			b.event.state.Store(int32(StateClosed))
+		}
+	})
+	return err
+}
+
+// State 返回WebSocket连接的状态。如果有单独的事件连接，则只有两条连接都已连接时才返回 StateConnected ，
+// 否则返回未连接的那条连接的状态。
+//
+// 通过 SetTransport 设置了其它的调用方式，并且从来没有建立过WebSocket连接时（例如 NewHttpBot ），返回 StateNoWebSocket
+func (b *Bot) State() ConnState {
+	state := ConnState(b.api.state.Load())
+	if state == StateConnecting && b.event == nil && b.transport.Load() != nil {
+		return StateNoWebSocket
 	}
-	return nil
+	if state == StateConnected && b.event != nil {
+		return ConnState(b.event.state.Load())
+	}
+	return state
 }
 
 // SetLimiter 设置限流器，limiterType为"wait"表示等待，为"drop"表示丢弃
@@ -194,7 +444,7 @@ func (b *Bot) SetLimiter(limiterType string, l *rate.Limiter) {
 	b.limiter.Store(&limiter{limiterType: limiterType, limiter: l})
 }
 
-// Run 如果不是并发方式启动，则此方法会将函数放入事件队列。如果是并发方式启动，则此方法等同于go f()。
+// Run 如果不是并发方式启动，则此方法会将函数放入事件队列。如果是并发方式或分片方式启动，则此方法等同于go f()。
 func (b *Bot) Run(f func()) {
 	if b.eventChan == nil {
 		go f()
@@ -203,47 +453,56 @@ func (b *Bot) Run(f func()) {
 	}
 }
 
+// SetTransport 设置调用API的方式，默认通过WebSocket连接调用
+func (b *Bot) SetTransport(t Transport) {
+	b.transport.Store(&t)
+}
+
 // request 发送请求
 func (b *Bot) request(action string, params any) (gjson.Result, error) {
-	limiter := b.limiter.Load()
-	if limiter != nil && !limiter.check() {
-		return gjson.Result{}, errors.New("rate limit exceeded")
+	parent := b.ctx
+	if parent == nil {
+		parent = context.Background()
 	}
-	msg := &requestMessage{
-		Echo:   b.echo.Add(1),
-		Action: action,
-		Params: params,
+	timeout := b.timeout
+	if timeout == 0 {
+		timeout = time.Duration(b.defaultTimeout.Load())
 	}
-	echo := msg.Echo
-	buf, err := json.Marshal(msg)
-	if err != nil {
-		slog.Error("json marshal failed", "error", err)
-		return gjson.Result{}, err
+	ctx := parent
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(parent, timeout)
+		defer cancel()
 	}
-	ch := make(chan gjson.Result, 1)
-	b.syncIdMap.Store(echo, ch)
-	c := b.c
-	if c == nil {
-		slog.Error("disconnected, send failed, please wait for reconnecting")
-		return gjson.Result{}, err
+	limiter := b.limiter.Load()
+	if limiter != nil && !limiter.check(ctx) {
+		return gjson.Result{}, ErrRateLimited
+	}
+	var t Transport = &wsTransport{b}
+	if p := b.transport.Load(); p != nil {
+		t = *p
 	}
-	err = c.WriteMessage(websocket.TextMessage, buf)
+	resp, err := t.Call(ctx, action, params)
 	if err != nil {
-		slog.Error("send error", "error", err)
+		if errors.Is(err, context.DeadlineExceeded) && parent.Err() == nil {
+			slog.Error("request timeout", "action", action)
+			return gjson.Result{}, ErrTimeout
+		}
 		return gjson.Result{}, err
 	}
-	slog.Debug("send", "msg", string(buf))
-	timeoutTimer := time.AfterFunc(5*time.Second, func() {
-		if ch, ok := b.syncIdMap.LoadAndDelete(echo); ok {
-			slog.Error("request timeout")
-			close(ch.(chan gjson.Result))
+	if retCode := resp.Get("retcode").Int(); retCode != RetcodeOk && retCode != RetcodeAsync {
+		err := &ActionError{
+			Action:  action,
+			Retcode: retCode,
+			Status:  resp.Get("status").String(),
+			Message: resp.Get("message").String(),
+			Wording: resp.Get("wording").String(),
+			Echo:    resp.Get("echo").Int(),
 		}
-	})
-	result, ok := <-ch
-	if !ok {
-		return gjson.Result{}, errors.New("request failed")
+		slog.Error("request failed", "error", err)
+		return gjson.Result{}, err
 	}
-	timeoutTimer.Stop()
+	result := resp.Get("data")
 	code := result.Get("code").Int()
 	if code != 0 {
 		e := fmt.Sprint("Non-zero code: ", code, ", error message: ", result.Get("msg"))
@@ -263,6 +522,9 @@ type quickOperationMessage struct {
 }
 
 func (b *Bot) quickOperation(context, operation any) error {
+	if slot, ok := b.quickOperationSlots.Load(context); ok && slot.(*quickOperationSlot).put(operation) {
+		return nil
+	}
 	if s, ok := context.(simplifier); ok {
 		context = s.simplify()
 	}
@@ -281,13 +543,91 @@ type requestMessage struct {
 
 var builder = make(map[string]map[string]func() any)
 
-type listenHandler func(message any) bool
+// RegisterEvent 注册自定义的事件类型（OneBot 11），之后就可以用 Listen 监听这种事件。已经存在的类型会被覆盖。
+//
+// postType 对应事件中的 post_type 字段，subType 对应事件中 <post_type>_type 字段，
+// 例如 go-cqhttp 的群精华消息事件为 RegisterEvent[EssenceNotice]("notice", "essence") 。
+// 需要在连接之前调用，例如在init函数中
+func RegisterEvent[T any](postType, subType string) {
+	registerEvent[T](builder, postType, subType)
+}
+
+// RegisterEventV12 注册自定义的事件类型（OneBot 12），postType 对应 type 字段，subType 对应 detail_type 字段
+func RegisterEventV12[T any](postType, subType string) {
+	registerEvent[T](builderV12, postType, subType)
+}
+
+func registerEvent[T any](builders map[string]map[string]func() any, postType, subType string) {
+	if builders[postType] == nil {
+		builders[postType] = make(map[string]func() any)
+	}
+	builders[postType][subType] = func() any { return new(T) }
+}
+
+// ListenRaw 监听所有事件的原始JSON，在解析为具体类型之前调用，l返回false时不再调用后续的所有监听者
+func (b *Bot) ListenRaw(l func(raw gjson.Result) bool) *Listener {
+	listener := b.newListener(false)
+	b.handlerLock.Lock()
+	defer b.handlerLock.Unlock()
+	b.rawHandler = insertListenEntry(b.rawHandler, listener, wrapListenHandler(listener, l))
+	return listener
+}
 
-func listen[M any](b *Bot, key, subKey string, l func(message M) bool) {
+// ListenUnknown 监听没有注册类型的事件的原始JSON，可以用来调试或者支持OneBot实现新增的事件
+func (b *Bot) ListenUnknown(l func(raw gjson.Result) bool) *Listener {
+	listener := b.newListener(false)
 	b.handlerLock.Lock()
 	defer b.handlerLock.Unlock()
-	if b.handler[key] == nil {
-		b.handler[key] = make(map[string][]listenHandler)
+	b.unknownHandler = insertListenEntry(b.unknownHandler, listener, wrapListenHandler(listener, l))
+	return listener
+}
+
+// Listen 监听T类型的事件，T可以是内置的事件类型，也可以是通过 RegisterEvent 注册的类型。
+//
+// 如果T没有注册则会panic
+func Listen[T any](b *Bot, l func(event *T) bool) *Listener {
+	return listenType(b.newListener(false), l)
+}
+
+// ListenOnce 同 Listen ，但是只会处理一次事件，处理之后自动移除
+func ListenOnce[T any](b *Bot, l func(event *T) bool) *Listener {
+	return listenType(b.newListener(true), l)
+}
+
+func listenType[T any](listener *Listener, l func(event *T) bool) *Listener {
+	found := false
+	for _, builders := range []map[string]map[string]func() any{builder, builderV12} {
+		for postType, m := range builders {
+			for subType, bd := range m {
+				if _, ok := bd().(*T); ok {
+					listener.add(postType, subType, wrapListenHandler(listener, l))
+					found = true
+				}
+			}
+		}
+	}
+	if !found {
+		panic(fmt.Sprintf("onebot: event type %T is not registered", new(T)))
+	}
+	return listener
+}
+
+type listenHandler func(message any) bool
+
+func listen[M any](b *Bot, key, subKey string, l func(message M) bool) *Listener {
+	listener := b.newListener(false)
+	listener.add(key, subKey, wrapListenHandler(listener, l))
+	return listener
+}
+
+// wrapListenHandler 把具体类型的监听函数包装为 listenHandler
+func wrapListenHandler[M any](listener *Listener, l func(message M) bool) listenHandler {
+	return func(m any) bool {
+		// OneBot 11 和 OneBot 12 的事件可能使用相同的 key ，类型不匹配时跳过
+		m0, ok := m.(M)
+		if !ok || !listener.acquire() {
+			return true
+		}
+		return l(m0)
 	}
-	b.handler[key][subKey] = append(b.handler[key][subKey], func(m any) bool { return l(m.(M)) })
 }
@@ -0,0 +1,93 @@
+package onebottest
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/CuteReimu/onebot"
+	"github.com/tidwall/gjson"
+)
+
+// Replayer 回放 onebot.Recorder 录制的消息，用于把线上的问题变成回归测试。
+//
+// 录制中收到的事件通过 Play 按顺序推送，API调用按照录制中同名API的调用顺序依次返回录制的响应，
+// 同名API的响应用完之后返回 onebot.RetcodeFailed
+//
+//	frames, err := onebot.ReadRecording(f)
+//	r := onebottest.NewReplayer(frames)
+//	defer r.Close()
+//	b, err := onebot.ConnectWithOptions(ctx, r.URL)
+//	// 注册监听...
+//	err = r.Play(ctx)
+type Replayer struct {
+	*Server
+	RealTime bool // 为true时按照录制时的时间间隔推送事件，默认尽快推送
+
+	events []*onebot.RecordedFrame
+}
+
+// NewReplayer 启动一个回放录制内容的服务端，使用完毕后需要调用 Close
+func NewReplayer(frames []*onebot.RecordedFrame) *Replayer {
+	r := &Replayer{Server: NewServer()}
+	actions := make(map[string]string) // echo -> action
+	responses := make(map[string][]gjson.Result)
+	for _, frame := range frames {
+		data := gjson.ParseBytes(frame.Data)
+		echo := data.Get("echo")
+		switch {
+		case frame.Direction == onebot.FrameOut:
+			actions[echo.Raw] = data.Get("action").String()
+		case echo.Exists():
+			if action, ok := actions[echo.Raw]; ok {
+				responses[action] = append(responses[action], data)
+			}
+		default:
+			if len(r.events) == 0 && data.Get("self_id").Exists() {
+				r.SelfId = data.Get("self_id").Int()
+			}
+			r.events = append(r.events, frame)
+		}
+	}
+	for action, results := range responses {
+		r.Handle(action, func(*Call) *Response {
+			// Handle 的处理函数在同一个连接上是依次调用的，但多个连接可能并发调用，需要加锁
+			r.lock.Lock()
+			defer r.lock.Unlock()
+			if len(results) == 0 {
+				return &Response{Retcode: onebot.RetcodeFailed, Message: "no recorded response"}
+			}
+			result := results[0]
+			results = results[1:]
+			resp := &Response{
+				Retcode: result.Get("retcode").Int(),
+				Status:  result.Get("status").String(),
+				Message: result.Get("message").String(),
+			}
+			if data := result.Get("data"); data.Exists() {
+				resp.Data = json.RawMessage(data.Raw)
+			}
+			return resp
+		})
+	}
+	return r
+}
+
+// Play 按顺序推送录制中的所有事件，全部推送完毕或者 ctx 结束时返回
+func (r *Replayer) Play(ctx context.Context) error {
+	for i, frame := range r.events {
+		if r.RealTime && i > 0 {
+			select {
+			case <-time.After(frame.Time.Sub(r.events[i-1].Time)):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		} else if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := r.PushEvent(frame.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
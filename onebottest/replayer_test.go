@@ -0,0 +1,80 @@
+package onebottest
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/CuteReimu/onebot"
+)
+
+func TestReplayer(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// 录制
+	s := NewServer()
+	defer s.Close()
+	s.SetResponse("get_group_info", &Response{Data: map[string]any{"group_id": 1000, "group_name": "测试群"}})
+	var buf bytes.Buffer
+	recorder := onebot.NewRecorder(&buf)
+	b, err := onebot.ConnectWithOptions(ctx, s.URL, onebot.WithRecorder(recorder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := make(chan string, 2)
+	b.ListenGroupMessage(func(message *onebot.GroupMessage) bool {
+		received <- message.RawMessage
+		return true
+	})
+	if _, err = b.GetGroupInfo(1000, false); err != nil {
+		t.Fatal(err)
+	}
+	for _, text := range []string{"first", "second"} {
+		if _, err = s.PushGroupMessage(1000, 2000, onebot.MessageChain{&onebot.Text{Text: text}}); err != nil {
+			t.Fatal(err)
+		}
+		<-received
+	}
+	_ = b.Close()
+	if err = recorder.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 回放
+	frames, err := onebot.ReadRecording(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 4 || frames[0].Direction != onebot.FrameOut || frames[1].Direction != onebot.FrameIn {
+		t.Fatal(frames)
+	}
+	r := NewReplayer(frames)
+	r.RealTime = true
+	defer r.Close()
+	b, err = onebot.ConnectWithOptions(ctx, r.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = b.Close() }()
+	b.ListenGroupMessage(func(message *onebot.GroupMessage) bool {
+		received <- message.RawMessage
+		return true
+	})
+	info, err := b.GetGroupInfo(1000, false)
+	if err != nil || info.GroupName != "测试群" {
+		t.Fatal(info, err)
+	}
+	if _, err = b.GetGroupInfo(1000, false); err == nil {
+		t.Fatal("expect error")
+	}
+	if err = r.Play(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"first", "second"} {
+		if text := <-received; text != expected {
+			t.Fatal(text)
+		}
+	}
+}
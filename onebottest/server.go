@@ -0,0 +1,357 @@
+// Package onebottest 提供一个模拟的 OneBot 11 正向WebSocket服务端，用于测试机器人的监听者和API调用，不需要真实的QQ客户端。
+//
+//	s := onebottest.NewServer()
+//	defer s.Close()
+//	b, err := onebot.ConnectWithOptions(ctx, s.URL)
+//	...
+//	s.SetResponse("send_group_msg", &onebottest.Response{Data: map[string]any{"message_id": 1}})
+//	_, err = s.PushGroupMessage(1000, 2000, onebot.MessageChain{&onebot.Text{Text: "hello"}})
+//	call, err := s.WaitCall(ctx, "send_group_msg")
+package onebottest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CuteReimu/onebot"
+	"github.com/gorilla/websocket"
+	"github.com/tidwall/gjson"
+)
+
+// DefaultSelfId 默认的机器人QQ号
+const DefaultSelfId = 10000
+
+// pushTimeout 推送事件时，等待机器人连接的最长时间
+const pushTimeout = 5 * time.Second
+
+// Call 收到的一次API调用
+type Call struct {
+	Action string          // API名称
+	Params gjson.Result    // 参数
+	Echo   json.RawMessage // echo 字段
+	Time   time.Time       // 收到的时间
+}
+
+// Response 对API调用的响应
+type Response struct {
+	Data       any           // 响应的 data 字段
+	Retcode    int64         // 返回码，不为0时 Status 默认为"failed"
+	Status     string        // 状态，为空时根据 Retcode 为"ok"或"failed"
+	Message    string        // 错误信息
+	Delay      time.Duration // 延迟多久之后再响应
+	Disconnect bool          // 为true时不响应，直接断开这个连接
+}
+
+// HandlerFunc 处理API调用，返回nil表示使用默认的响应
+type HandlerFunc func(call *Call) *Response
+
+// Server 模拟的 OneBot 11 服务端
+type Server struct {
+	URL         string // 连接地址，例如 ws://127.0.0.1:12345
+	SelfId      int64  // 机器人的QQ号，默认为 DefaultSelfId ，会作为 get_login_info 的默认响应和事件中的 self_id
+	AccessToken string // 如果不为空，则要求连接时提供相同的 access token
+
+	server    *httptest.Server
+	upgrader  websocket.Upgrader
+	lock      sync.Mutex
+	conns     map[*serverConn]struct{}
+	connected chan struct{} // 有连接时关闭，所有连接都断开时重新创建
+	handlers  map[string]HandlerFunc
+	calls     []*Call
+	callCond  chan struct{} // 收到API调用时关闭并重新创建，用于 WaitCall
+	messageId int32
+}
+
+// serverConn 一个连接，写入需要加锁
+type serverConn struct {
+	*websocket.Conn
+	lock  sync.Mutex
+	event bool // 是否接收事件
+}
+
+func (c *serverConn) write(buf []byte) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.WriteMessage(websocket.TextMessage, buf)
+}
+
+// NewServer 启动一个模拟的服务端，使用完毕后需要调用 Close
+func NewServer() *Server {
+	s := &Server{
+		SelfId:    DefaultSelfId,
+		conns:     make(map[*serverConn]struct{}),
+		connected: make(chan struct{}),
+		handlers:  make(map[string]HandlerFunc),
+		callCond:  make(chan struct{}),
+	}
+	s.server = httptest.NewServer(s)
+	s.URL = "ws" + strings.TrimPrefix(s.server.URL, "http")
+	return s
+}
+
+// Close 断开所有连接并关闭服务端
+func (s *Server) Close() {
+	s.Disconnect()
+	s.server.Close()
+}
+
+// Disconnect 断开当前所有的连接，可以用来测试断线重连
+func (s *Server) Disconnect() {
+	s.lock.Lock()
+	conns := make([]*serverConn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	s.lock.Unlock()
+	for _, c := range conns {
+		_ = c.Close()
+	}
+}
+
+// ServeHTTP 实现 http.Handler ，路径为 /api 的连接不接收事件
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if len(s.AccessToken) > 0 {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if len(token) == 0 {
+			token = r.URL.Query().Get("access_token")
+		}
+		if token != s.AccessToken {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+	}
+	ws, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	c := &serverConn{Conn: ws, event: !strings.HasSuffix(r.URL.Path, "/api")}
+	s.lock.Lock()
+	if len(s.conns) == 0 {
+		close(s.connected)
+	}
+	s.conns[c] = struct{}{}
+	s.lock.Unlock()
+	defer func() {
+		_ = c.Close()
+		s.lock.Lock()
+		delete(s.conns, c)
+		if len(s.conns) == 0 {
+			s.connected = make(chan struct{})
+		}
+		s.lock.Unlock()
+	}()
+	for {
+		_, message, err := c.ReadMessage()
+		if err != nil {
+			return
+		}
+		msg := gjson.ParseBytes(message)
+		call := &Call{
+			Action: msg.Get("action").String(),
+			Params: msg.Get("params"),
+			Echo:   json.RawMessage(msg.Get("echo").Raw),
+			Time:   time.Now(),
+		}
+		s.lock.Lock()
+		s.calls = append(s.calls, call)
+		close(s.callCond)
+		s.callCond = make(chan struct{})
+		h := s.handlers[call.Action]
+		s.lock.Unlock()
+		var resp *Response
+		if h != nil {
+			resp = h(call)
+		}
+		if resp == nil {
+			resp = s.defaultResponse(call)
+		}
+		if resp.Disconnect {
+			return
+		}
+		if resp.Delay > 0 {
+			go func() {
+				time.Sleep(resp.Delay)
+				_ = c.write(s.encodeResponse(call, resp))
+			}()
+		} else if err = c.write(s.encodeResponse(call, resp)); err != nil {
+			return
+		}
+	}
+}
+
+// defaultResponse 没有设置响应时的默认响应
+func (s *Server) defaultResponse(call *Call) *Response {
+	if call.Action == "get_login_info" {
+		return &Response{Data: map[string]any{"user_id": s.SelfId, "nickname": "bot"}}
+	}
+	return &Response{}
+}
+
+func (s *Server) encodeResponse(call *Call, resp *Response) []byte {
+	status := resp.Status
+	if len(status) == 0 {
+		status = "ok"
+		if resp.Retcode != 0 {
+			status = "failed"
+		}
+	}
+	echo := call.Echo
+	if len(echo) == 0 {
+		echo = json.RawMessage("null")
+	}
+	buf, _ := json.Marshal(map[string]any{
+		"status":  status,
+		"retcode": resp.Retcode,
+		"data":    resp.Data,
+		"message": resp.Message,
+		"wording": resp.Message,
+		"echo":    echo,
+	})
+	return buf
+}
+
+// Handle 设置某个API的处理函数，会覆盖之前的设置
+func (s *Server) Handle(action string, h HandlerFunc) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.handlers[action] = h
+}
+
+// SetResponse 设置某个API固定的响应，会覆盖之前的设置
+func (s *Server) SetResponse(action string, resp *Response) {
+	s.Handle(action, func(*Call) *Response { return resp })
+}
+
+// Calls 返回收到的所有API调用
+func (s *Server) Calls() []*Call {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]*Call(nil), s.calls...)
+}
+
+// CallsOf 返回收到的某个API的所有调用
+func (s *Server) CallsOf(action string) []*Call {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var ret []*Call
+	for _, call := range s.calls {
+		if call.Action == action {
+			ret = append(ret, call)
+		}
+	}
+	return ret
+}
+
+// ClearCalls 清空记录的API调用
+func (s *Server) ClearCalls() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.calls = nil
+}
+
+// WaitCall 等待并返回某个API的第一次调用，如果已经调用过则直接返回。监听者中调用的API是异步的，需要用这个方法等待
+func (s *Server) WaitCall(ctx context.Context, action string) (*Call, error) {
+	for {
+		s.lock.Lock()
+		for _, call := range s.calls {
+			if call.Action == action {
+				s.lock.Unlock()
+				return call, nil
+			}
+		}
+		cond := s.callCond
+		s.lock.Unlock()
+		select {
+		case <-cond:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// PushEvent 向所有接收事件的连接推送事件，event会被序列化为JSON，如果是 []byte 或 json.RawMessage 则原样发送。
+// 如果还没有连接，则最多等待5秒
+func (s *Server) PushEvent(event any) error {
+	var buf []byte
+	switch e := event.(type) {
+	case []byte:
+		buf = e
+	case json.RawMessage:
+		buf = e
+	default:
+		var err error
+		if buf, err = json.Marshal(event); err != nil {
+			return err
+		}
+	}
+	s.lock.Lock()
+	connected := s.connected
+	s.lock.Unlock()
+	select {
+	case <-connected:
+	case <-time.After(pushTimeout):
+		return errors.New("no connection")
+	}
+	s.lock.Lock()
+	conns := make([]*serverConn, 0, len(s.conns))
+	for c := range s.conns {
+		if c.event {
+			conns = append(conns, c)
+		}
+	}
+	s.lock.Unlock()
+	var err error
+	for _, c := range conns {
+		err = errors.Join(err, c.write(buf))
+	}
+	return err
+}
+
+// nextMessageId 生成消息ID
+func (s *Server) nextMessageId() int32 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.messageId++
+	return s.messageId
+}
+
+// PushGroupMessage 推送一条群消息，返回消息ID
+func (s *Server) PushGroupMessage(groupId, userId int64, message onebot.MessageChain) (int32, error) {
+	m := &onebot.GroupMessage{
+		Time:        time.Now().Unix(),
+		SelfId:      s.SelfId,
+		PostType:    "message",
+		MessageType: "group",
+		SubType:     onebot.GroupMessageNormal,
+		MessageId:   s.nextMessageId(),
+		GroupId:     groupId,
+		UserId:      userId,
+		Message:     message,
+		RawMessage:  message.CQString(),
+		Sender:      onebot.Member{UserId: userId, Role: onebot.RoleMember},
+	}
+	return m.MessageId, s.PushEvent(m)
+}
+
+// PushPrivateMessage 推送一条好友私聊消息，返回消息ID
+func (s *Server) PushPrivateMessage(userId int64, message onebot.MessageChain) (int32, error) {
+	m := &onebot.PrivateMessage{
+		Time:        time.Now().Unix(),
+		SelfId:      s.SelfId,
+		PostType:    "message",
+		MessageType: "private",
+		SubType:     onebot.PrivateMessageFriend,
+		MessageId:   s.nextMessageId(),
+		UserId:      userId,
+		Message:     message,
+		RawMessage:  message.CQString(),
+		Sender:      onebot.Profile{UserId: userId},
+	}
+	return m.MessageId, s.PushEvent(m)
+}
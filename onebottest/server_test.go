@@ -0,0 +1,61 @@
+package onebottest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/CuteReimu/onebot"
+)
+
+func TestServer(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	b, err := onebot.ConnectWithOptions(ctx, s.URL, onebot.WithReconnectInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = b.Close() }()
+	info, err := b.GetLoginInfo()
+	if err != nil || info.UserId != DefaultSelfId {
+		t.Fatal(info, err)
+	}
+
+	s.SetResponse("send_group_msg", &Response{Data: map[string]any{"message_id": 123}})
+	b.ListenGroupMessage(func(message *onebot.GroupMessage) bool {
+		_, _ = b.SendGroupMessage(message.GroupId, onebot.MessageChain{&onebot.Text{Text: "echo: " + message.RawMessage}})
+		return true
+	})
+	if _, err = s.PushGroupMessage(1000, 2000, onebot.MessageChain{&onebot.Text{Text: "hello"}}); err != nil {
+		t.Fatal(err)
+	}
+	call, err := s.WaitCall(ctx, "send_group_msg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if call.Params.Get("group_id").Int() != 1000 || call.Params.Get("message.0.data.text").String() != "echo: hello" {
+		t.Fatal(call.Params.Raw)
+	}
+
+	s.SetResponse("delete_msg", &Response{Retcode: 100, Message: "not found"})
+	var actionError *onebot.ActionError
+	if err = b.DeleteMessage(1); !errors.As(err, &actionError) || actionError.Retcode != 100 || actionError.Message != "not found" {
+		t.Fatal(err)
+	}
+
+	s.SetResponse("get_friend_list", &Response{Delay: 200 * time.Millisecond})
+	if _, err = b.WithTimeout(50 * time.Millisecond).GetFriendList(); !errors.Is(err, onebot.ErrTimeout) {
+		t.Fatal(err)
+	}
+
+	s.SetResponse("get_group_list", &Response{Disconnect: true})
+	if _, err = b.GetGroupList(); !errors.Is(err, onebot.ErrDisconnected) {
+		t.Fatal(err)
+	}
+	if len(s.CallsOf("get_group_list")) != 1 {
+		t.Fatal(s.CallsOf("get_group_list"))
+	}
+}
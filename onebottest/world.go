@@ -0,0 +1,648 @@
+package onebottest
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/CuteReimu/onebot"
+	"github.com/tidwall/gjson"
+)
+
+// World 模拟的QQ世界，保存好友、群、群成员和消息记录。
+//
+// 创建之后会接管 Server 上与之相关的API，调用API会修改状态并推送相应的事件，例如 set_group_ban 会推送 onebot.GroupBanNotice 。
+// 也可以通过 GroupSay 等方法模拟其他人的操作
+//
+//	w := onebottest.NewWorld(s)
+//	w.AddGroup(1000, "测试群", onebot.RoleAdmin)
+//	w.AddMember(1000, 2000, "张三", onebot.RoleMember)
+//	_, err := w.GroupSay(1000, 2000, onebot.MessageChain{&onebot.Text{Text: "广告"}})
+type World struct {
+	server   *Server
+	lock     sync.Mutex
+	friends  map[int64]*onebot.Friend
+	groups   map[int64]*worldGroup
+	messages map[int32]*worldMessage
+}
+
+type worldGroup struct {
+	info     onebot.GroupInfo
+	members  map[int64]*worldMember
+	wholeBan bool
+}
+
+type worldMember struct {
+	info     onebot.GroupMemberInfo
+	banUntil time.Time
+}
+
+func (m *worldMember) banned() bool {
+	return time.Now().Before(m.banUntil)
+}
+
+type worldMessage struct {
+	message onebot.Message
+	groupId int64 // 私聊消息为0
+	userId  int64 // 私聊消息为对方的QQ号，群消息为发送者的QQ号
+}
+
+// ErrPermissionDenied 机器人或者成员没有权限进行操作，对应的API返回 onebot.RetcodeFailed
+var ErrPermissionDenied = errors.New("permission denied")
+
+// NewWorld 创建一个空的世界，并接管 Server 上的相关API
+func NewWorld(s *Server) *World {
+	w := &World{
+		server:   s,
+		friends:  make(map[int64]*onebot.Friend),
+		groups:   make(map[int64]*worldGroup),
+		messages: make(map[int32]*worldMessage),
+	}
+	handlers := map[string]func(params gjson.Result) (any, error){
+		"send_private_msg":        w.handleSendPrivateMessage,
+		"send_group_msg":          w.handleSendGroupMessage,
+		"send_msg":                w.handleSendMessage,
+		"delete_msg":              w.handleDeleteMessage,
+		"get_msg":                 w.handleGetMessage,
+		"set_group_kick":          w.handleSetGroupKick,
+		"set_group_ban":           w.handleSetGroupBan,
+		"set_group_whole_ban":     w.handleSetGroupWholeBan,
+		"set_group_admin":         w.handleSetGroupAdmin,
+		"set_group_card":          w.handleSetGroupCard,
+		"set_group_name":          w.handleSetGroupName,
+		"set_group_leave":         w.handleSetGroupLeave,
+		"set_group_special_title": w.handleSetGroupSpecialTitle,
+		"get_friend_list":         w.handleGetFriendList,
+		"get_group_info":          w.handleGetGroupInfo,
+		"get_group_list":          w.handleGetGroupList,
+		"get_group_member_info":   w.handleGetGroupMemberInfo,
+		"get_group_member_list":   w.handleGetGroupMemberList,
+	}
+	for action, h := range handlers {
+		s.Handle(action, func(call *Call) *Response {
+			data, err := h(call.Params)
+			if err != nil {
+				return &Response{Retcode: onebot.RetcodeFailed, Message: err.Error()}
+			}
+			return &Response{Data: data}
+		})
+	}
+	return w
+}
+
+// AddFriend 添加好友，不推送事件
+func (w *World) AddFriend(userId int64, nickname string) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.friends[userId] = &onebot.Friend{UserId: userId, Nickname: nickname}
+}
+
+// AddGroup 添加一个群，机器人在群里的角色为botRole，不推送事件
+func (w *World) AddGroup(groupId int64, name string, botRole onebot.Role) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	g := &worldGroup{
+		info:    onebot.GroupInfo{GroupId: groupId, GroupName: name, MaxMemberCount: 500},
+		members: make(map[int64]*worldMember),
+	}
+	w.groups[groupId] = g
+	w.addMember(g, w.server.SelfId, "bot", botRole)
+}
+
+// AddMember 向群里添加成员，不推送事件，群不存在时什么也不做
+func (w *World) AddMember(groupId, userId int64, nickname string, role onebot.Role) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if g := w.groups[groupId]; g != nil {
+		w.addMember(g, userId, nickname, role)
+	}
+}
+
+func (w *World) addMember(g *worldGroup, userId int64, nickname string, role onebot.Role) {
+	g.members[userId] = &worldMember{info: onebot.GroupMemberInfo{
+		GroupId:        g.info.GroupId,
+		UserId:         userId,
+		Nickname:       nickname,
+		JoinTime:       int32(time.Now().Unix()),
+		Level:          "1",
+		Role:           role,
+		CardChangeable: true,
+	}}
+	g.info.MemberCount = int32(len(g.members))
+}
+
+// JoinGroup 模拟有人加群，推送 onebot.GroupIncreaseNotice
+func (w *World) JoinGroup(groupId, userId int64, nickname string) error {
+	w.lock.Lock()
+	g := w.groups[groupId]
+	if g == nil {
+		w.lock.Unlock()
+		return errors.New("group not found")
+	}
+	w.addMember(g, userId, nickname, onebot.RoleMember)
+	w.lock.Unlock()
+	return w.server.PushEvent(&onebot.GroupIncreaseNotice{
+		Time:       time.Now().Unix(),
+		SelfId:     w.server.SelfId,
+		PostType:   "notice",
+		NoticeType: "group_increase",
+		SubType:    onebot.GroupIncreaseNoticeApprove,
+		GroupId:    groupId,
+		UserId:     userId,
+	})
+}
+
+// Group 获取群信息，不存在时返回nil
+func (w *World) Group(groupId int64) *onebot.GroupInfo {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if g := w.groups[groupId]; g != nil {
+		info := g.info
+		return &info
+	}
+	return nil
+}
+
+// Member 获取群成员信息，不存在时返回nil
+func (w *World) Member(groupId, userId int64) *onebot.GroupMemberInfo {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if m := w.member(groupId, userId); m != nil {
+		info := m.info
+		return &info
+	}
+	return nil
+}
+
+func (w *World) member(groupId, userId int64) *worldMember {
+	if g := w.groups[groupId]; g != nil {
+		return g.members[userId]
+	}
+	return nil
+}
+
+// IsBanned 群成员是否正在被禁言，包括全员禁言
+func (w *World) IsBanned(groupId, userId int64) bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.isBanned(groupId, userId)
+}
+
+func (w *World) isBanned(groupId, userId int64) bool {
+	m := w.member(groupId, userId)
+	if m == nil {
+		return false
+	}
+	return m.banned() || w.groups[groupId].wholeBan && m.info.Role == onebot.RoleMember
+}
+
+// History 获取群的消息记录，按照发送顺序排列，不包括已经撤回的消息。groupId为0时获取所有私聊消息
+func (w *World) History(groupId int64) []*onebot.Message {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	var ret []*onebot.Message
+	for _, m := range w.messages {
+		if m.groupId == groupId {
+			message := m.message
+			ret = append(ret, &message)
+		}
+	}
+	slices.SortFunc(ret, func(a, b *onebot.Message) int { return cmp.Compare(a.MessageId, b.MessageId) })
+	return ret
+}
+
+// GroupSay 模拟群成员发送群消息，记录在消息记录中并推送 onebot.GroupMessage ，返回消息ID。
+// 不是群成员或者被禁言时返回错误
+func (w *World) GroupSay(groupId, userId int64, message onebot.MessageChain) (int32, error) {
+	w.lock.Lock()
+	m := w.member(groupId, userId)
+	if m == nil {
+		w.lock.Unlock()
+		return 0, errors.New("member not found")
+	}
+	if w.isBanned(groupId, userId) {
+		w.lock.Unlock()
+		return 0, ErrPermissionDenied
+	}
+	id := w.addMessage(groupId, userId, userId, "group", message)
+	event := &onebot.GroupMessage{
+		Time:        time.Now().Unix(),
+		SelfId:      w.server.SelfId,
+		PostType:    "message",
+		MessageType: "group",
+		SubType:     onebot.GroupMessageNormal,
+		MessageId:   id,
+		GroupId:     groupId,
+		UserId:      userId,
+		Message:     message,
+		RawMessage:  message.CQString(),
+		Sender: onebot.Member{
+			UserId:   userId,
+			Nickname: m.info.Nickname,
+			Card:     m.info.Card,
+			Level:    string(m.info.Level),
+			Role:     m.info.Role,
+		},
+	}
+	w.lock.Unlock()
+	return id, w.server.PushEvent(event)
+}
+
+// PrivateSay 模拟好友发送私聊消息，记录在消息记录中并推送 onebot.PrivateMessage ，返回消息ID
+func (w *World) PrivateSay(userId int64, message onebot.MessageChain) (int32, error) {
+	w.lock.Lock()
+	f := w.friends[userId]
+	if f == nil {
+		w.lock.Unlock()
+		return 0, errors.New("friend not found")
+	}
+	id := w.addMessage(0, userId, userId, "private", message)
+	event := &onebot.PrivateMessage{
+		Time:        time.Now().Unix(),
+		SelfId:      w.server.SelfId,
+		PostType:    "message",
+		MessageType: "private",
+		SubType:     onebot.PrivateMessageFriend,
+		MessageId:   id,
+		UserId:      userId,
+		Message:     message,
+		RawMessage:  message.CQString(),
+		Sender:      onebot.Profile{UserId: userId, Nickname: f.Nickname},
+	}
+	w.lock.Unlock()
+	return id, w.server.PushEvent(event)
+}
+
+// addMessage 记录消息，senderId为发送者的QQ号，需要持有锁
+func (w *World) addMessage(groupId, userId, senderId int64, messageType onebot.MessageType, message onebot.MessageChain) int32 {
+	id := w.server.nextMessageId()
+	sender := onebot.Profile{UserId: senderId}
+	if m := w.member(groupId, senderId); m != nil {
+		sender.Nickname = m.info.Nickname
+		m.info.LastSentTime = int32(time.Now().Unix())
+	} else if f := w.friends[senderId]; f != nil {
+		sender.Nickname = f.Nickname
+	}
+	w.messages[id] = &worldMessage{
+		message: onebot.Message{
+			Time:        int32(time.Now().Unix()),
+			MessageType: messageType,
+			MessageId:   id,
+			RealId:      id,
+			Sender:      sender,
+			Message:     message,
+		},
+		groupId: groupId,
+		userId:  userId,
+	}
+	return id
+}
+
+// parseMessage 解析API参数中的消息，auto_escape 为true时字符串按纯文本处理
+func parseMessage(params gjson.Result) (onebot.MessageChain, error) {
+	message := params.Get("message")
+	if message.Type == gjson.String && params.Get("auto_escape").Bool() {
+		return onebot.MessageChain{&onebot.Text{Text: message.String()}}, nil
+	}
+	var chain onebot.MessageChain
+	err := json.Unmarshal([]byte(message.Raw), &chain)
+	return chain, err
+}
+
+func (w *World) handleSendPrivateMessage(params gjson.Result) (any, error) {
+	message, err := parseMessage(params)
+	if err != nil {
+		return nil, err
+	}
+	userId := params.Get("user_id").Int()
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.friends[userId] == nil {
+		return nil, errors.New("friend not found")
+	}
+	id := w.addMessage(0, userId, w.server.SelfId, "private", message)
+	return map[string]any{"message_id": id}, nil
+}
+
+func (w *World) handleSendGroupMessage(params gjson.Result) (any, error) {
+	message, err := parseMessage(params)
+	if err != nil {
+		return nil, err
+	}
+	groupId := params.Get("group_id").Int()
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.member(groupId, w.server.SelfId) == nil {
+		return nil, errors.New("group not found")
+	}
+	if w.isBanned(groupId, w.server.SelfId) {
+		return nil, ErrPermissionDenied
+	}
+	id := w.addMessage(groupId, w.server.SelfId, w.server.SelfId, "group", message)
+	return map[string]any{"message_id": id}, nil
+}
+
+func (w *World) handleSendMessage(params gjson.Result) (any, error) {
+	messageType := params.Get("message_type").String()
+	if messageType == "group" || len(messageType) == 0 && params.Get("group_id").Exists() {
+		return w.handleSendGroupMessage(params)
+	}
+	return w.handleSendPrivateMessage(params)
+}
+
+func (w *World) handleDeleteMessage(params gjson.Result) (any, error) {
+	id := int32(params.Get("message_id").Int())
+	w.lock.Lock()
+	m := w.messages[id]
+	if m == nil {
+		w.lock.Unlock()
+		return nil, errors.New("message not found")
+	}
+	var event any
+	if m.groupId != 0 {
+		senderId := m.message.Sender.UserId
+		if senderId != w.server.SelfId && !w.canManage(m.groupId, senderId) {
+			w.lock.Unlock()
+			return nil, ErrPermissionDenied
+		}
+		event = &onebot.GroupRecallNotice{
+			Time:       time.Now().Unix(),
+			SelfId:     w.server.SelfId,
+			PostType:   "notice",
+			NoticeType: "group_recall",
+			GroupId:    m.groupId,
+			UserId:     senderId,
+			OperatorId: w.server.SelfId,
+			MessageId:  int64(id),
+		}
+	} else {
+		event = &onebot.FriendRecallNotice{
+			Time:       time.Now().Unix(),
+			SelfId:     w.server.SelfId,
+			PostType:   "notice",
+			NoticeType: "friend_recall",
+			UserId:     m.userId,
+			MessageId:  int64(id),
+		}
+	}
+	delete(w.messages, id)
+	w.lock.Unlock()
+	return nil, w.server.PushEvent(event)
+}
+
+func (w *World) handleGetMessage(params gjson.Result) (any, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	m := w.messages[int32(params.Get("message_id").Int())]
+	if m == nil {
+		return nil, errors.New("message not found")
+	}
+	message := m.message
+	return &message, nil
+}
+
+// canManage 机器人是否可以管理群成员，群主可以管理所有人，管理员可以管理普通成员，需要持有锁
+func (w *World) canManage(groupId, userId int64) bool {
+	self, m := w.member(groupId, w.server.SelfId), w.member(groupId, userId)
+	if self == nil || m == nil || userId == w.server.SelfId {
+		return false
+	}
+	switch self.info.Role {
+	case onebot.RoleOwner:
+		return true
+	case onebot.RoleAdmin:
+		return m.info.Role == onebot.RoleMember
+	default:
+		return false
+	}
+}
+
+func (w *World) handleSetGroupKick(params gjson.Result) (any, error) {
+	groupId, userId := params.Get("group_id").Int(), params.Get("user_id").Int()
+	w.lock.Lock()
+	if !w.canManage(groupId, userId) {
+		w.lock.Unlock()
+		return nil, ErrPermissionDenied
+	}
+	g := w.groups[groupId]
+	delete(g.members, userId)
+	g.info.MemberCount = int32(len(g.members))
+	w.lock.Unlock()
+	return nil, w.server.PushEvent(&onebot.GroupDecreaseNotice{
+		Time:       time.Now().Unix(),
+		SelfId:     w.server.SelfId,
+		PostType:   "notice",
+		NoticeType: "group_decrease",
+		SubType:    onebot.GroupDecreaseNoticeKick,
+		GroupId:    groupId,
+		OperatorId: w.server.SelfId,
+		UserId:     userId,
+	})
+}
+
+func (w *World) handleSetGroupBan(params gjson.Result) (any, error) {
+	groupId, userId := params.Get("group_id").Int(), params.Get("user_id").Int()
+	duration := params.Get("duration")
+	seconds := duration.Int()
+	if !duration.Exists() {
+		seconds = 30 * 60
+	}
+	w.lock.Lock()
+	if !w.canManage(groupId, userId) {
+		w.lock.Unlock()
+		return nil, ErrPermissionDenied
+	}
+	subType := onebot.GroupBanNoticeBan
+	if seconds > 0 {
+		w.member(groupId, userId).banUntil = time.Now().Add(time.Duration(seconds) * time.Second)
+	} else {
+		subType = onebot.GroupBanNoticeLiftBan
+		w.member(groupId, userId).banUntil = time.Time{}
+	}
+	w.lock.Unlock()
+	return nil, w.server.PushEvent(&onebot.GroupBanNotice{
+		Time:       time.Now().Unix(),
+		SelfId:     w.server.SelfId,
+		PostType:   "notice",
+		NoticeType: "group_ban",
+		SubType:    subType,
+		GroupId:    groupId,
+		OperatorId: w.server.SelfId,
+		UserId:     userId,
+		Duration:   seconds,
+	})
+}
+
+func (w *World) handleSetGroupWholeBan(params gjson.Result) (any, error) {
+	groupId := params.Get("group_id").Int()
+	enable := params.Get("enable")
+	w.lock.Lock()
+	self := w.member(groupId, w.server.SelfId)
+	if self == nil || self.info.Role == onebot.RoleMember {
+		w.lock.Unlock()
+		return nil, ErrPermissionDenied
+	}
+	w.groups[groupId].wholeBan = !enable.Exists() || enable.Bool()
+	subType := onebot.GroupBanNoticeBan
+	if !w.groups[groupId].wholeBan {
+		subType = onebot.GroupBanNoticeLiftBan
+	}
+	w.lock.Unlock()
+	return nil, w.server.PushEvent(&onebot.GroupBanNotice{
+		Time:       time.Now().Unix(),
+		SelfId:     w.server.SelfId,
+		PostType:   "notice",
+		NoticeType: "group_ban",
+		SubType:    subType,
+		GroupId:    groupId,
+		OperatorId: w.server.SelfId,
+		Duration:   -1,
+	})
+}
+
+func (w *World) handleSetGroupAdmin(params gjson.Result) (any, error) {
+	groupId, userId := params.Get("group_id").Int(), params.Get("user_id").Int()
+	enable := params.Get("enable")
+	w.lock.Lock()
+	self, m := w.member(groupId, w.server.SelfId), w.member(groupId, userId)
+	if self == nil || m == nil || self.info.Role != onebot.RoleOwner || m.info.Role == onebot.RoleOwner {
+		w.lock.Unlock()
+		return nil, ErrPermissionDenied
+	}
+	subType := onebot.GroupAdminNoticeSet
+	if !enable.Exists() || enable.Bool() {
+		m.info.Role = onebot.RoleAdmin
+	} else {
+		subType = onebot.GroupAdminNoticeUnset
+		m.info.Role = onebot.RoleMember
+	}
+	w.lock.Unlock()
+	return nil, w.server.PushEvent(&onebot.GroupAdminNotice{
+		Time:       time.Now().Unix(),
+		SelfId:     w.server.SelfId,
+		PostType:   "notice",
+		NoticeType: "group_admin",
+		SubType:    subType,
+		GroupId:    groupId,
+		UserId:     userId,
+	})
+}
+
+func (w *World) handleSetGroupCard(params gjson.Result) (any, error) {
+	groupId, userId := params.Get("group_id").Int(), params.Get("user_id").Int()
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if userId != w.server.SelfId && !w.canManage(groupId, userId) {
+		return nil, ErrPermissionDenied
+	}
+	m := w.member(groupId, userId)
+	if m == nil {
+		return nil, errors.New("member not found")
+	}
+	m.info.Card = params.Get("card").String()
+	return nil, nil
+}
+
+func (w *World) handleSetGroupName(params gjson.Result) (any, error) {
+	groupId := params.Get("group_id").Int()
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	self := w.member(groupId, w.server.SelfId)
+	if self == nil || self.info.Role == onebot.RoleMember {
+		return nil, ErrPermissionDenied
+	}
+	w.groups[groupId].info.GroupName = params.Get("group_name").String()
+	return nil, nil
+}
+
+func (w *World) handleSetGroupLeave(params gjson.Result) (any, error) {
+	groupId := params.Get("group_id").Int()
+	w.lock.Lock()
+	if w.groups[groupId] == nil {
+		w.lock.Unlock()
+		return nil, errors.New("group not found")
+	}
+	delete(w.groups, groupId)
+	w.lock.Unlock()
+	return nil, w.server.PushEvent(&onebot.GroupDecreaseNotice{
+		Time:       time.Now().Unix(),
+		SelfId:     w.server.SelfId,
+		PostType:   "notice",
+		NoticeType: "group_decrease",
+		SubType:    onebot.GroupDecreaseNoticeLeave,
+		GroupId:    groupId,
+		OperatorId: w.server.SelfId,
+		UserId:     w.server.SelfId,
+	})
+}
+
+func (w *World) handleSetGroupSpecialTitle(params gjson.Result) (any, error) {
+	groupId, userId := params.Get("group_id").Int(), params.Get("user_id").Int()
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	self, m := w.member(groupId, w.server.SelfId), w.member(groupId, userId)
+	if self == nil || m == nil || self.info.Role != onebot.RoleOwner {
+		return nil, ErrPermissionDenied
+	}
+	m.info.Title = params.Get("special_title").String()
+	return nil, nil
+}
+
+func (w *World) handleGetFriendList(gjson.Result) (any, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	ret := make([]*onebot.Friend, 0, len(w.friends))
+	for _, f := range w.friends {
+		f2 := *f
+		ret = append(ret, &f2)
+	}
+	slices.SortFunc(ret, func(a, b *onebot.Friend) int { return cmp.Compare(a.UserId, b.UserId) })
+	return ret, nil
+}
+
+func (w *World) handleGetGroupInfo(params gjson.Result) (any, error) {
+	info := w.Group(params.Get("group_id").Int())
+	if info == nil {
+		return nil, errors.New("group not found")
+	}
+	return info, nil
+}
+
+func (w *World) handleGetGroupList(gjson.Result) (any, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	ret := make([]*onebot.GroupInfo, 0, len(w.groups))
+	for _, g := range w.groups {
+		info := g.info
+		ret = append(ret, &info)
+	}
+	slices.SortFunc(ret, func(a, b *onebot.GroupInfo) int { return cmp.Compare(a.GroupId, b.GroupId) })
+	return ret, nil
+}
+
+func (w *World) handleGetGroupMemberInfo(params gjson.Result) (any, error) {
+	info := w.Member(params.Get("group_id").Int(), params.Get("user_id").Int())
+	if info == nil {
+		return nil, errors.New("member not found")
+	}
+	return info, nil
+}
+
+func (w *World) handleGetGroupMemberList(params gjson.Result) (any, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	g := w.groups[params.Get("group_id").Int()]
+	if g == nil {
+		return nil, errors.New("group not found")
+	}
+	ret := make([]*onebot.GroupMemberInfo, 0, len(g.members))
+	for _, m := range g.members {
+		info := m.info
+		ret = append(ret, &info)
+	}
+	slices.SortFunc(ret, func(a, b *onebot.GroupMemberInfo) int { return cmp.Compare(a.UserId, b.UserId) })
+	return ret, nil
+}
@@ -0,0 +1,99 @@
+package onebottest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/CuteReimu/onebot"
+)
+
+func TestWorld(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	w := NewWorld(s)
+	w.AddGroup(1000, "测试群", onebot.RoleAdmin)
+	w.AddMember(1000, 2000, "张三", onebot.RoleMember)
+	w.AddMember(1000, 3000, "群主", onebot.RoleOwner)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	b, err := onebot.ConnectWithOptions(ctx, s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = b.Close() }()
+
+	// 一个简单的管理机器人：有人发广告就撤回并禁言
+	banned := make(chan *onebot.GroupBanNotice, 1)
+	recalled := make(chan *onebot.GroupRecallNotice, 1)
+	b.ListenGroupMessage(func(message *onebot.GroupMessage) bool {
+		if message.RawMessage == "广告" {
+			_ = b.DeleteMessage(int64(message.MessageId))
+			_ = b.SetGroupBan(message.GroupId, message.UserId, 600)
+		}
+		return true
+	})
+	b.ListenGroupBanNotice(func(notice *onebot.GroupBanNotice) bool {
+		banned <- notice
+		return true
+	})
+	b.ListenGroupRecallNotice(func(notice *onebot.GroupRecallNotice) bool {
+		recalled <- notice
+		return true
+	})
+	messageId, err := w.GroupSay(1000, 2000, onebot.MessageChain{&onebot.Text{Text: "广告"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case notice := <-recalled:
+		if notice.MessageId != int64(messageId) || notice.UserId != 2000 {
+			t.Fatal(notice)
+		}
+	case <-ctx.Done():
+		t.Fatal("recall notice not received")
+	}
+	select {
+	case notice := <-banned:
+		if notice.UserId != 2000 || notice.Duration != 600 || notice.SubType != onebot.GroupBanNoticeBan {
+			t.Fatal(notice)
+		}
+	case <-ctx.Done():
+		t.Fatal("ban notice not received")
+	}
+	if !w.IsBanned(1000, 2000) || len(w.History(1000)) != 0 {
+		t.Fatal(w.History(1000))
+	}
+	if _, err = w.GroupSay(1000, 2000, onebot.MessageChain{&onebot.Text{Text: "hello"}}); err != ErrPermissionDenied {
+		t.Fatal(err)
+	}
+
+	// 管理员不能禁言群主
+	if err = b.SetGroupBan(1000, 3000, 600); err == nil {
+		t.Fatal("expect error")
+	}
+
+	if err = b.SetGroupCard(1000, 2000, "广告哥"); err != nil {
+		t.Fatal(err)
+	}
+	id, err := b.SendGroupMessage(1000, onebot.MessageChain{&onebot.Text{Text: "请勿发广告"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, err := b.GetMessage(int32(id))
+	if err != nil || message.Sender.UserId != DefaultSelfId || message.Message.CQString() != "请勿发广告" {
+		t.Fatal(message, err)
+	}
+
+	if err = b.SetGroupKick(1000, 2000, false); err != nil {
+		t.Fatal(err)
+	}
+	members, err := b.GetGroupMemberList(1000)
+	if err != nil || len(members) != 2 || members[0].UserId != 3000 || members[1].UserId != DefaultSelfId {
+		t.Fatal(members, err)
+	}
+	info, err := b.GetGroupInfo(1000, false)
+	if err != nil || info.MemberCount != 2 {
+		t.Fatal(info, err)
+	}
+}
@@ -0,0 +1,222 @@
+package onebot
+
+import (
+	"context"
+	"crypto/tls"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Option ConnectWithOptions 的连接选项
+type Option func(*options)
+
+type options struct {
+	qq              int64
+	concurrentEvent bool
+	shard           *ShardOptions
+	accessToken     string
+	eventAddr       string
+	version         ProtocolVersion
+	header          http.Header
+	tlsConfig       *tls.Config
+	dialTimeout     time.Duration
+	proxy           func(*http.Request) (*url.URL, error)
+	dialer          *websocket.Dialer
+	readLimit       int64
+	writeTimeout    time.Duration
+	reconnect       ReconnectPolicy
+	waitReconnect   bool
+	logger          *slog.Logger
+	recorder        *Recorder
+	beforeRead      func(b *Bot) // 内部使用，首次连接成功后、开始读取消息之前同步调用
+	onConnected     func(b *Bot)
+	onDisconnected  func(b *Bot, err error)
+	onReconnecting  func(b *Bot, attempt int, delay time.Duration)
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{reconnect: DefaultReconnectPolicy, writeTimeout: defaultWriteTimeout}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.logger == nil {
+		o.logger = slog.Default()
+	}
+	return o
+}
+
+// newDialer 根据选项生成 websocket.Dialer ，不会修改 WithDialer 传入的对象
+func (o *options) newDialer() *websocket.Dialer {
+	var d websocket.Dialer
+	if o.dialer != nil {
+		d = *o.dialer
+	} else {
+		d = *websocket.DefaultDialer
+	}
+	if o.tlsConfig != nil {
+		d.TLSClientConfig = o.tlsConfig
+	}
+	if o.dialTimeout > 0 {
+		d.HandshakeTimeout = o.dialTimeout
+	}
+	if o.proxy != nil {
+		d.Proxy = o.proxy
+	}
+	return &d
+}
+
+// dialFunc 返回连接 addr 的函数，连接失败时会打印日志
+func (o *options) dialFunc(addr string, log *slog.Logger) func(ctx context.Context) (*websocket.Conn, error) {
+	dialer := o.newDialer()
+	header := o.newHeader()
+	return func(ctx context.Context) (*websocket.Conn, error) {
+		c, resp, err := dialer.DialContext(ctx, addr, header) // nolint:bodyclose
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+		if err != nil {
+			log.Error("Connect failed", "error", err)
+			return nil, err
+		}
+		if o.readLimit > 0 {
+			c.SetReadLimit(o.readLimit)
+		}
+		log.Info("Connected successfully")
+		return c, nil
+	}
+}
+
+// newHeader 生成握手时的HTTP头
+func (o *options) newHeader() http.Header {
+	header := o.header.Clone()
+	if len(o.accessToken) > 0 {
+		if header == nil {
+			header = make(http.Header)
+		}
+		header.Set("Authorization", "Bearer "+o.accessToken)
+	}
+	return header
+}
+
+// WithQQ 设置机器人的QQ号，即 Bot.QQ
+func WithQQ(qq int64) Option {
+	return func(o *options) { o.qq = qq }
+}
+
+// WithConcurrentEvent 含义同 Connect 的 concurrentEvent 参数，默认为false。
+// 即使为false，正在被 WaitNext 等待的事件也会在新的协程中经过中间件，见 Connect 的说明
+func WithConcurrentEvent(concurrentEvent bool) Option {
+	return func(o *options) { o.concurrentEvent = concurrentEvent }
+}
+
+// WithShardedEvent 分片处理事件，同一个群或者同一个人的事件按顺序处理，不同的群之间并发处理，
+// 设置后忽略 WithConcurrentEvent 。正在被 WaitNext 等待的事件会在新的协程中经过中间件，见 Connect 的说明
+func WithShardedEvent(opts ShardOptions) Option {
+	return func(o *options) { o.shard = &opts }
+}
+
+// WithRecorder 录制收发的所有WebSocket消息，同 Bot.SetRecorder
+func WithRecorder(r *Recorder) Option {
+	return func(o *options) { o.recorder = r }
+}
+
+// WithAccessToken 设置 access token ，会通过 Authorization 头发送
+func WithAccessToken(accessToken string) Option {
+	return func(o *options) { o.accessToken = accessToken }
+}
+
+// WithProtocolVersion 设置OneBot标准的版本，默认为 ProtocolV11 。
+// 使用 ProtocolV12 时，请使用 ListenXxxV12 系列方法监听事件，使用 XxxV12 系列方法调用API
+func WithProtocolVersion(version ProtocolVersion) Option {
+	return func(o *options) { o.version = version }
+}
+
+// WithEventAddr 设置单独的事件连接地址，例如 ws://127.0.0.1:8080/event ，
+// 此时 ConnectWithOptions 的 addr 参数应当是API连接的地址，例如 ws://127.0.0.1:8080/api
+func WithEventAddr(addr string) Option {
+	return func(o *options) { o.eventAddr = addr }
+}
+
+// WithHeader 设置握手时额外发送的HTTP头，可以多次调用
+func WithHeader(header http.Header) Option {
+	return func(o *options) {
+		if o.header == nil {
+			o.header = make(http.Header)
+		}
+		for k, v := range header {
+			o.header[k] = append(o.header[k], v...)
+		}
+	}
+}
+
+// WithTLSConfig 设置 wss:// 连接使用的TLS配置
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(o *options) { o.tlsConfig = tlsConfig }
+}
+
+// WithDialTimeout 设置建立连接（包括握手）的超时时间
+func WithDialTimeout(timeout time.Duration) Option {
+	return func(o *options) { o.dialTimeout = timeout }
+}
+
+// WithProxy 设置代理，例如 http.ProxyFromEnvironment 或 http.ProxyURL(u)
+func WithProxy(proxy func(*http.Request) (*url.URL, error)) Option {
+	return func(o *options) { o.proxy = proxy }
+}
+
+// WithDialer 使用自定义的 websocket.Dialer ， WithTLSConfig 、 WithDialTimeout 、 WithProxy 会覆盖其中对应的设置
+func WithDialer(dialer *websocket.Dialer) Option {
+	return func(o *options) { o.dialer = dialer }
+}
+
+// WithReadLimit 设置单条消息的最大字节数，超过时连接会被断开
+func WithReadLimit(limit int64) Option {
+	return func(o *options) { o.readLimit = limit }
+}
+
+// WithWriteTimeout 设置写超时时间，默认为10秒，超时后连接会被断开并重连。timeout小于等于0表示不超时
+func WithWriteTimeout(timeout time.Duration) Option {
+	return func(o *options) { o.writeTimeout = timeout }
+}
+
+// WithReconnectInterval 设置固定的断线重连间隔，不限重连次数
+func WithReconnectInterval(interval time.Duration) Option {
+	return func(o *options) { o.reconnect = ReconnectPolicy{InitialInterval: interval, Multiplier: 1} }
+}
+
+// WithReconnectPolicy 设置断线重连策略，默认为 DefaultReconnectPolicy
+func WithReconnectPolicy(policy ReconnectPolicy) Option {
+	return func(o *options) { o.reconnect = policy }
+}
+
+// WithWaitReconnect 设置断线时调用API是否等待重连成功，默认为false，即立即返回 ErrDisconnected 。
+// 等待时仍然受超时时间和 Bot.WithContext 的控制
+func WithWaitReconnect(wait bool) Option {
+	return func(o *options) { o.waitReconnect = wait }
+}
+
+// WithOnConnected 设置连接成功（包括首次连接和重连）时的回调。有单独的事件连接时，任意一条连接重连成功都会调用。
+//
+// 回调在新的协程中调用，此时已经开始读取消息，可以调用API，回调返回之前收到的事件会在返回之后再处理
+func WithOnConnected(f func(b *Bot)) Option {
+	return func(o *options) { o.onConnected = f }
+}
+
+// WithOnDisconnected 设置连接断开时的回调，主动调用 Bot.Close 时不会调用
+func WithOnDisconnected(f func(b *Bot, err error)) Option {
+	return func(o *options) { o.onDisconnected = f }
+}
+
+// WithOnReconnecting 设置每次尝试重连前的回调，attempt是第几次尝试（从1开始），delay是本次等待的时间
+func WithOnReconnecting(f func(b *Bot, attempt int, delay time.Duration)) Option {
+	return func(o *options) { o.onReconnecting = f }
+}
+
+// WithLogger 设置连接使用的日志，默认使用 slog.Default()
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) { o.logger = logger }
+}
@@ -0,0 +1,116 @@
+package onebot
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/tidwall/gjson"
+)
+
+func TestConnectOptions(t *testing.T) {
+	headers := make(chan http.Header, 1)
+	var upgrader websocket.Upgrader
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header.Clone()
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() { _ = c.Close() }()
+		for {
+			_, message, err := c.ReadMessage()
+			if err != nil {
+				return
+			}
+			var resp string
+			if gjson.GetBytes(message, "action").String() == "get_status" {
+				// 超过 WithReadLimit 的消息会导致断线
+				resp = fmt.Sprintf(`{"status":"ok","retcode":0,"data":{"padding":"%s"},"echo":%d}`,
+					strings.Repeat("x", 1024), gjson.GetBytes(message, "echo").Int())
+			} else {
+				resp = fmt.Sprintf(`{"status":"ok","retcode":0,"data":{"user_id":1,"nickname":"bot"},"echo":%d}`,
+					gjson.GetBytes(message, "echo").Int())
+			}
+			if err = c.WriteMessage(websocket.TextMessage, []byte(resp)); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+	addr := "wss" + strings.TrimPrefix(server.URL, "https")
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// 没有信任服务端的证书时无法连接
+	if _, err := ConnectWithOptions(ctx, addr, WithReconnectInterval(time.Hour)); err == nil {
+		t.Fatal("expect certificate error")
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	var dialed, proxied atomic.Int32
+	dialer := &websocket.Dialer{
+		NetDialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dialed.Add(1)
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}
+	disconnected := make(chan error, 1)
+	b, err := ConnectWithOptions(ctx, addr,
+		WithDialer(dialer),
+		WithTLSConfig(&tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}),
+		WithProxy(func(*http.Request) (*url.URL, error) {
+			proxied.Add(1)
+			return nil, nil // 不使用代理，只检查是否被调用
+		}),
+		WithAccessToken("token"),
+		WithHeader(http.Header{"X-Test": {"a"}}),
+		WithHeader(http.Header{"X-Test": {"b"}, "User-Agent": {"onebot-test"}}),
+		WithReadLimit(512),
+		WithReconnectInterval(time.Hour),
+		WithOnDisconnected(func(_ *Bot, err error) { disconnected <- err }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = b.Close() }()
+	header := <-headers
+	if header.Get("Authorization") != "Bearer token" || strings.Join(header.Values("X-Test"), ",") != "a,b" ||
+		header.Get("User-Agent") != "onebot-test" {
+		t.Fatal(header)
+	}
+	if dialed.Load() != 1 || proxied.Load() != 1 {
+		t.Fatal(dialed.Load(), proxied.Load())
+	}
+	if dialer.TLSClientConfig != nil {
+		t.Fatal("dialer passed to WithDialer should not be modified")
+	}
+	info, err := b.GetLoginInfo()
+	if err != nil || info.Nickname != "bot" {
+		t.Fatal(info, err)
+	}
+	if _, err = b.GetStatus(); !errors.Is(err, ErrDisconnected) {
+		t.Fatal(err)
+	}
+	select {
+	case err = <-disconnected:
+		if !errors.Is(err, websocket.ErrReadLimit) {
+			t.Fatal(err)
+		}
+	case <-ctx.Done():
+		t.Fatal("read limit not applied")
+	}
+}
@@ -0,0 +1,85 @@
+package onebot
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// FrameDirection 录制的消息的方向
+type FrameDirection string
+
+const (
+	FrameIn  FrameDirection = "in"  // 从OneBot实现收到的消息，包括事件和API的响应
+	FrameOut FrameDirection = "out" // 发送给OneBot实现的API请求
+)
+
+// RecordedFrame 录制的一条WebSocket消息，录制文件中每行是一个 RecordedFrame 的JSON
+type RecordedFrame struct {
+	Time      time.Time       `json:"time"`      // 收到或发送的时间
+	Direction FrameDirection  `json:"direction"` // 方向
+	Data      json.RawMessage `json:"data"`      // 消息内容
+}
+
+// Recorder 把 Bot 收发的所有WebSocket消息以JSONL格式写入 io.Writer ，可以用 onebottest.Replayer 回放
+//
+//	f, _ := os.Create("record.jsonl")
+//	defer f.Close()
+//	b, err := onebot.ConnectWithOptions(ctx, addr, onebot.WithRecorder(onebot.NewRecorder(f)))
+type Recorder struct {
+	lock sync.Mutex
+	enc  *json.Encoder
+	err  error
+}
+
+// NewRecorder 新建一个 Recorder ，可以同时给多个 Bot 使用
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{enc: json.NewEncoder(w)}
+}
+
+// Err 返回第一次写入失败的错误，写入失败后不再写入
+func (r *Recorder) Err() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.err
+}
+
+func (r *Recorder) record(direction FrameDirection, data []byte) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.err != nil {
+		return
+	}
+	r.err = r.enc.Encode(&RecordedFrame{Time: time.Now(), Direction: direction, Data: data})
+}
+
+// ReadRecording 读取 Recorder 录制的所有消息
+func ReadRecording(r io.Reader) ([]*RecordedFrame, error) {
+	var frames []*RecordedFrame
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var frame *RecordedFrame
+		if err := json.Unmarshal(scanner.Bytes(), &frame); err != nil {
+			return nil, err
+		}
+		frames = append(frames, frame)
+	}
+	return frames, scanner.Err()
+}
+
+// SetRecorder 设置录制收发消息的 Recorder ，为nil表示不录制
+func (b *Bot) SetRecorder(r *Recorder) {
+	b.recorder.Store(r)
+}
+
+func (b *Bot) record(direction FrameDirection, data []byte) {
+	if r := b.recorder.Load(); r != nil {
+		r.record(direction, data)
+	}
+}
@@ -0,0 +1,197 @@
+package onebot
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// ReverseOptions 反向WebSocket服务端的配置
+type ReverseOptions struct {
+	// AccessToken 如果不为空，则要求OneBot实现连接时通过 Authorization 头或 access_token 参数提供相同的值
+	AccessToken string
+
+	// ConcurrentEvent 含义同 Connect 的 concurrentEvent 参数，对每个连接进来的账号生效
+	ConcurrentEvent bool
+
+	// ShardedEvent 如果不为nil，则分片处理事件，含义同 WithShardedEvent ，设置后忽略 ConcurrentEvent
+	ShardedEvent *ShardOptions
+
+	// ProtocolVersion OneBot标准的版本，默认为 ProtocolV11 。
+	// OneBot 12 的实现连接时可能不发送 X-Self-ID ，此时同一个实现的所有连接共用QQ号为0的 Bot
+	ProtocolVersion ProtocolVersion
+
+	// OnConnect 某个账号第一次连接上来时在新的协程中调用，可以在此注册监听和调用API，返回之前收到的事件会在返回之后再处理。
+	// 同一个账号断线重连或者同时建立 api 和 event 连接时，不会再次调用。
+	// 如果之前的 Bot 已经被关闭，则重新连接时会新建一个 Bot 并再次调用。
+	// 如果第一条连接是 event 连接，调用API时会等待 api 连接建立
+	OnConnect func(b *Bot)
+}
+
+// ReverseServer 反向WebSocket服务端，由OneBot实现主动连接过来。
+// 同一个账号的所有连接共用一个 Bot
+type ReverseServer struct {
+	opts     ReverseOptions
+	upgrader websocket.Upgrader
+	lock     sync.Mutex
+	bots     map[int64]*Bot
+	conns    map[*websocket.Conn]struct{}
+	server   *http.Server
+}
+
+// NewReverseServer 新建一个反向WebSocket服务端，它实现了 http.Handler，可以自行挂载到已有的http服务上
+func NewReverseServer(opts ReverseOptions) *ReverseServer {
+	return &ReverseServer{
+		opts:  opts,
+		bots:  make(map[int64]*Bot),
+		conns: make(map[*websocket.Conn]struct{}),
+	}
+}
+
+// ListenReverse 在 addr 上监听反向WebSocket连接，接受 / 、 /api 、 /event 三种路径
+func ListenReverse(addr string, opts ReverseOptions) (*ReverseServer, error) {
+	s := NewReverseServer(opts)
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s.server = &http.Server{Handler: s} // nolint:gosec
+	go func() {
+		if err := s.server.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("reverse server stopped", "error", err)
+		}
+	}()
+	slog.Info("Listening reverse websocket", "addr", l.Addr().String())
+	return s, nil
+}
+
+// Bot 根据QQ号获取已经连接上来的 Bot，如果不存在或者已经关闭则返回nil
+func (s *ReverseServer) Bot(selfId int64) *Bot {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if b := s.bots[selfId]; b != nil && !b.closed.Load() {
+		return b
+	}
+	return nil
+}
+
+// Close 关闭服务端以及所有已建立的连接
+func (s *ReverseServer) Close() error {
+	var err error
+	if s.server != nil {
+		err = s.server.Close()
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for c := range s.conns {
+		_ = c.Close()
+	}
+	for _, b := range s.bots {
+		_ = b.Close()
+	}
+	return err
+}
+
+func (s *ReverseServer) checkAccessToken(r *http.Request) int {
+	if len(s.opts.AccessToken) == 0 {
+		return 0
+	}
+	token := r.URL.Query().Get("access_token")
+	if auth := r.Header.Get("Authorization"); len(auth) > 0 {
+		_, token, _ = strings.Cut(auth, " ")
+	}
+	if len(token) == 0 {
+		return http.StatusUnauthorized
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.opts.AccessToken)) != 1 {
+		return http.StatusForbidden
+	}
+	return 0
+}
+
+func (s *ReverseServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var role string
+	switch strings.TrimSuffix(r.URL.Path, "/") {
+	case "":
+		role = "Universal"
+	case "/" + WsChannelApi:
+		role = "API"
+	case "/" + WsChannelEvent:
+		role = "Event"
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if h := r.Header.Get("X-Client-Role"); len(h) > 0 {
+		role = h
+	}
+	if code := s.checkAccessToken(r); code != 0 {
+		http.Error(w, http.StatusText(code), code)
+		return
+	}
+	var selfId int64
+	if h := r.Header.Get("X-Self-ID"); len(h) > 0 || s.opts.ProtocolVersion != ProtocolV12 {
+		var err error
+		if selfId, err = strconv.ParseInt(h, 10, 64); err != nil {
+			http.Error(w, "invalid X-Self-ID", http.StatusBadRequest)
+			return
+		}
+	}
+	c, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		slog.Error("upgrade failed", "error", err)
+		return
+	}
+	log := slog.With("self_id", selfId, "role", role)
+	log.Info("Reverse connection accepted", "remote", r.RemoteAddr)
+	s.lock.Lock()
+	b, isNew := s.bots[selfId], false
+	if b == nil || b.closed.Load() {
+		// 已经关闭的 Bot 不再使用，重新连接时新建一个
+		b, isNew = newBot(selfId, s.opts.ConcurrentEvent || s.opts.ShardedEvent != nil, defaultWriteTimeout), true
+		if s.opts.ShardedEvent != nil {
+			b.shards = newShards(*s.opts.ShardedEvent, b.done)
+		}
+		b.version = s.opts.ProtocolVersion
+		b.waitReconnect = true // 反向WebSocket的 api 和 event 连接可能先后建立，断线后也由OneBot实现负责重连
+		s.bots[selfId] = b
+	}
+	s.conns[c] = struct{}{}
+	s.lock.Unlock()
+	if role != "Event" {
+		b.api.set(c)
+	}
+	if isNew && s.opts.OnConnect != nil {
+		b.runHook(s.opts.OnConnect)
+	}
+	defer func() {
+		s.lock.Lock()
+		delete(s.conns, c)
+		s.lock.Unlock()
+		if b.closed.Load() {
+			b.clearConn(b.api, c, StateClosed)
+		} else {
+			b.clearConn(b.api, c, StateDisconnected)
+		}
+		_ = c.Close()
+		log.Info("Reverse connection closed")
+	}()
+	for !b.closed.Load() {
+		t, message, err := c.ReadMessage()
+		if err != nil {
+			log.Error("read error", "error", err)
+			return
+		}
+		if t != websocket.TextMessage {
+			continue
+		}
+		b.onMessage(log, message)
+	}
+}
@@ -0,0 +1,215 @@
+package onebot
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/tidwall/gjson"
+)
+
+// dialReverse 模拟OneBot实现连接反向WebSocket服务端
+func dialReverse(t *testing.T, addr, path string, header http.Header) (*websocket.Conn, int) {
+	c, resp, err := websocket.DefaultDialer.Dial(addr+path, header)
+	if err != nil {
+		if resp == nil {
+			t.Fatal(err)
+		}
+		return nil, resp.StatusCode
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c, resp.StatusCode
+}
+
+// serveApi 模拟OneBot实现处理API请求
+func serveApi(c *websocket.Conn, selfId int64) {
+	for {
+		_, message, err := c.ReadMessage()
+		if err != nil {
+			return
+		}
+		resp := fmt.Sprintf(`{"status":"ok","retcode":0,"data":{"user_id":%d,"nickname":"bot"},"echo":%d}`, selfId, gjson.GetBytes(message, "echo").Int())
+		if err = c.WriteMessage(websocket.TextMessage, []byte(resp)); err != nil {
+			return
+		}
+	}
+}
+
+func TestReverseServerAuth(t *testing.T) {
+	s := NewReverseServer(ReverseOptions{AccessToken: "token"})
+	server := httptest.NewServer(s)
+	defer server.Close()
+	defer func() { _ = s.Close() }()
+	addr := "ws" + strings.TrimPrefix(server.URL, "http")
+	tests := []struct {
+		name   string
+		path   string
+		header http.Header
+		code   int
+	}{
+		{"no token", "/", http.Header{"X-Self-ID": {"10001"}}, http.StatusUnauthorized},
+		{"wrong token", "/", http.Header{"X-Self-ID": {"10001"}, "Authorization": {"Bearer wrong"}}, http.StatusForbidden},
+		{"wrong query token", "/?access_token=wrong", http.Header{"X-Self-ID": {"10001"}}, http.StatusForbidden},
+		{"no self id", "/", http.Header{"Authorization": {"Bearer token"}}, http.StatusBadRequest},
+		{"invalid self id", "/", http.Header{"X-Self-ID": {"abc"}, "Authorization": {"Bearer token"}}, http.StatusBadRequest},
+		{"unknown path", "/foo", http.Header{"X-Self-ID": {"10001"}, "Authorization": {"Bearer token"}}, http.StatusNotFound},
+		{"header token", "/", http.Header{"X-Self-ID": {"10001"}, "Authorization": {"Bearer token"}}, http.StatusSwitchingProtocols},
+		{"query token", "/?access_token=token", http.Header{"X-Self-ID": {"10002"}}, http.StatusSwitchingProtocols},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, code := dialReverse(t, addr, tt.path, tt.header); code != tt.code {
+				t.Fatal(code)
+			}
+		})
+	}
+}
+
+func TestReverseServer(t *testing.T) {
+	type result struct {
+		b    *Bot
+		info *LoginInfo
+		err  error
+	}
+	connected := make(chan result, 2)
+	events := make(chan int64, 1)
+	s := NewReverseServer(ReverseOptions{OnConnect: func(b *Bot) {
+		b.ListenFriendAddNotice(func(notice *FriendAddNotice) bool {
+			events <- notice.UserId
+			return true
+		})
+		info, err := b.GetLoginInfo() // 钩子中可以调用API
+		connected <- result{b, info, err}
+	}})
+	server := httptest.NewServer(s)
+	defer server.Close()
+	defer func() { _ = s.Close() }()
+	addr := "ws" + strings.TrimPrefix(server.URL, "http")
+	header := http.Header{"X-Self-ID": {"10001"}}
+
+	// 先建立 event 连接并推送事件，钩子返回之后才会处理，所以不会错过
+	ec, _ := dialReverse(t, addr, "/event", header)
+	if err := ec.WriteMessage(websocket.TextMessage, []byte(`{"post_type":"notice","notice_type":"friend_add","self_id":10001,"user_id":2000}`)); err != nil {
+		t.Fatal(err)
+	}
+	ac, _ := dialReverse(t, addr, "/api", header)
+	go serveApi(ac, 10001)
+	var r result
+	select {
+	case r = <-connected:
+	case <-time.After(3 * time.Second):
+		t.Fatal("OnConnect not called")
+	}
+	if r.err != nil || r.info.UserId != 10001 || r.b.QQ != 10001 || s.Bot(10001) != r.b {
+		t.Fatal(r.info, r.err)
+	}
+	select {
+	case userId := <-events:
+		if userId != 2000 {
+			t.Fatal(userId)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("event not received")
+	}
+
+	// 同一个账号的其它连接共用一个 Bot ，不再调用 OnConnect ，API请求只通过 api 连接发送
+	ec2, _ := dialReverse(t, addr, "/event", header)
+	if err := ec2.WriteMessage(websocket.TextMessage, []byte(`{"post_type":"notice","notice_type":"friend_add","self_id":10001,"user_id":3000}`)); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case userId := <-events:
+		if userId != 3000 {
+			t.Fatal(userId)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("event not received")
+	}
+	if info, err := r.b.GetLoginInfo(); err != nil || info.UserId != 10001 {
+		t.Fatal(info, err)
+	}
+	select {
+	case <-connected:
+		t.Fatal("OnConnect called twice")
+	default:
+	}
+
+	// 另一个账号是独立的 Bot
+	ac2, _ := dialReverse(t, addr, "/", http.Header{"X-Self-ID": {"10002"}})
+	go serveApi(ac2, 10002)
+	var r2 result
+	select {
+	case r2 = <-connected:
+		if r2.err != nil || r2.b == r.b || r2.b.QQ != 10002 || s.Bot(10002) != r2.b {
+			t.Fatal(r2.info, r2.err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("OnConnect not called")
+	}
+
+	// Bot 被关闭之后重新连接，会新建一个 Bot 并再次调用 OnConnect
+	_ = r2.b.Close()
+	if s.Bot(10002) != nil {
+		t.Fatal("closed bot should not be returned")
+	}
+	ac3, _ := dialReverse(t, addr, "/", http.Header{"X-Self-ID": {"10002"}})
+	go serveApi(ac3, 10002)
+	select {
+	case r3 := <-connected:
+		if r3.err != nil || r3.b == r2.b || r3.b.QQ != 10002 || s.Bot(10002) != r3.b {
+			t.Fatal(r3.info, r3.err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("OnConnect not called after the bot was closed")
+	}
+}
+
+func TestListenReverse(t *testing.T) {
+	// 找一个空闲的端口
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostPort := l.Addr().String()
+	_ = l.Close()
+	m := NewBotManager()
+	defer func() { _ = m.CloseAll() }()
+	setup := make(chan *LoginInfo, 1)
+	m.OnBot(func(b *Bot) {
+		info, err := b.GetLoginInfo()
+		if err != nil {
+			t.Error(err)
+		}
+		setup <- info
+	})
+	s, err := m.ListenReverse(hostPort, ReverseOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = s.Close() }()
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	var c *websocket.Conn
+	for c == nil {
+		c, _, err = websocket.DefaultDialer.DialContext(ctx, "ws://"+hostPort+"/", http.Header{"X-Self-ID": {"10001"}})
+		if ctx.Err() != nil {
+			t.Fatal(err)
+		}
+	}
+	defer func() { _ = c.Close() }()
+	go serveApi(c, 10001)
+	select {
+	case info := <-setup:
+		if info == nil || info.UserId != 10001 || m.Get(10001) == nil {
+			t.Fatal(info)
+		}
+	case <-ctx.Done():
+		t.Fatal("OnBot not called")
+	}
+}
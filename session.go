@@ -0,0 +1,183 @@
+package onebot
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// waiter 一个正在等待事件的 WaitNext
+type waiter struct {
+	match func(m any) bool
+	ch    chan any
+}
+
+// hasWaiter 在读取消息的协程中调用，返回是否有 waiter 正在等待这个事件
+func hasWaiter(waiters []*waiter, m any) bool {
+	return slices.ContainsFunc(waiters, func(w *waiter) bool { return w.match(m) })
+}
+
+// intercept 在所有中间件之后调用，把事件交给第一个匹配的 waiter ，返回是否被截获。
+// 正在被等待的事件不经过 Bot.Run ，所以在监听者中等待下一条消息也不会死锁
+func (b *Bot) intercept(m any) bool {
+	b.handlerLock.RLock()
+	waiters := b.waiters
+	b.handlerLock.RUnlock()
+	for _, w := range waiters {
+		if w.match(m) && b.removeWaiter(w) {
+			w.ch <- m
+			return true
+		}
+	}
+	return false
+}
+
+// removeWaiter 移除 waiter ，如果已经被移除了则返回false
+func (b *Bot) removeWaiter(w *waiter) bool {
+	b.handlerLock.Lock()
+	defer b.handlerLock.Unlock()
+	i := slices.Index(b.waiters, w)
+	if i < 0 {
+		return false
+	}
+	b.waiters = slices.Delete(slices.Clone(b.waiters), i, i+1)
+	return true
+}
+
+// WaitNext 等待下一个满足filter的T类型的事件，filter为nil表示不过滤。
+// 事件要先经过 Bot.Use 添加的中间件，被中间件拦截的事件不会被等到。
+// 等到的事件不会再交给 Listen 等注册的普通监听者，但是 ListenRaw 的监听者仍然能收到。
+//
+// 可以在监听者中调用，单线程处理事件时，等待期间其它的事件会排队等到这个监听者返回后再处理。
+// filter可能会被调用多次，其中一次在读取消息的协程中，不能阻塞。ctx 结束或 Bot 关闭时返回错误
+func WaitNext[T any](ctx context.Context, b *Bot, filter func(event *T) bool) (*T, error) {
+	return addWaiter(b, filter).wait(ctx, b)
+}
+
+// typedWaiter 等待T类型事件的 waiter
+type typedWaiter[T any] struct {
+	*waiter
+}
+
+// addWaiter 开始等待，在事件可能很快到来的情况下，需要在触发事件之前调用
+func addWaiter[T any](b *Bot, filter func(event *T) bool) typedWaiter[T] {
+	w := &waiter{
+		match: func(m any) bool {
+			m0, ok := m.(*T)
+			return ok && (filter == nil || filter(m0))
+		},
+		ch: make(chan any, 1),
+	}
+	b.handlerLock.Lock()
+	b.waiters = append(slices.Clip(b.waiters), w)
+	b.handlerLock.Unlock()
+	return typedWaiter[T]{w}
+}
+
+func (w typedWaiter[T]) wait(ctx context.Context, b *Bot) (*T, error) {
+	var err error
+	select {
+	case m := <-w.ch:
+		return m.(*T), nil
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-b.done:
+		err = ErrDisconnected
+	}
+	if !b.removeWaiter(w.waiter) {
+		// 已经被截获了，不能丢掉
+		return (<-w.ch).(*T), nil
+	}
+	return nil, err
+}
+
+// WaitNextMessage 等待下一条满足filter的群消息，同 WaitNext
+//
+//	b.ListenGroupMessage(func(message *onebot.GroupMessage) bool {
+//		// 等待同一个人在同一个群里的回复
+//		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+//		defer cancel()
+//		reply, err := b.WaitNextMessage(ctx, func(m *onebot.GroupMessage) bool {
+//			return m.GroupId == message.GroupId && m.UserId == message.UserId
+//		})
+//		...
+//	})
+func (b *Bot) WaitNextMessage(ctx context.Context, filter func(message *GroupMessage) bool) (*GroupMessage, error) {
+	return WaitNext(ctx, b, filter)
+}
+
+// WaitNextPrivateMessage 等待下一条满足filter的私聊消息，同 WaitNext
+func (b *Bot) WaitNextPrivateMessage(ctx context.Context, filter func(message *PrivateMessage) bool) (*PrivateMessage, error) {
+	return WaitNext(ctx, b, filter)
+}
+
+// Session 与某个群里的某个人或者某个好友的对话，用于实现多轮对话
+//
+//	s := b.NewSession(message.GroupId, message.UserId)
+//	s.Timeout = time.Minute
+//	answer, err := s.Ask(ctx, onebot.MessageChain{&onebot.Text{Text: "请输入你的选择"}})
+type Session struct {
+	Bot     *Bot
+	GroupId int64         // 群号，为0表示私聊
+	UserId  int64         // QQ号，群聊时为0表示群里的任何人
+	Timeout time.Duration // 每次等待消息的超时时间，小于等于0表示只受 ctx 控制
+}
+
+// NewSession 新建一个对话，groupId为0表示私聊
+func (b *Bot) NewSession(groupId, userId int64) *Session {
+	return &Session{Bot: b, GroupId: groupId, UserId: userId}
+}
+
+// Next 等待对方的下一条消息，返回消息内容
+func (s *Session) Next(ctx context.Context) (MessageChain, error) {
+	return s.wait(ctx, s.addWaiter())
+}
+
+// Send 在对话中发送消息，返回消息id
+func (s *Session) Send(message MessageChain) (int64, error) {
+	if s.GroupId == 0 {
+		return s.Bot.SendPrivateMessage(s.UserId, message)
+	}
+	return s.Bot.SendGroupMessage(s.GroupId, message)
+}
+
+// Ask 发送消息并等待对方的下一条消息，在发送之前就开始等待，所以不会错过很快的回复
+func (s *Session) Ask(ctx context.Context, message MessageChain) (MessageChain, error) {
+	w := s.addWaiter()
+	if _, err := s.Send(message); err != nil {
+		s.Bot.removeWaiter(w)
+		return nil, err
+	}
+	return s.wait(ctx, w)
+}
+
+func (s *Session) addWaiter() *waiter {
+	if s.GroupId == 0 {
+		return addWaiter(s.Bot, func(message *PrivateMessage) bool {
+			return message.UserId == s.UserId
+		}).waiter
+	}
+	return addWaiter(s.Bot, func(message *GroupMessage) bool {
+		return message.GroupId == s.GroupId && (s.UserId == 0 || message.UserId == s.UserId)
+	}).waiter
+}
+
+func (s *Session) wait(ctx context.Context, w *waiter) (MessageChain, error) {
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+	if s.GroupId == 0 {
+		message, err := typedWaiter[PrivateMessage]{w}.wait(ctx, s.Bot)
+		if err != nil {
+			return nil, err
+		}
+		return message.Message, nil
+	}
+	message, err := typedWaiter[GroupMessage]{w}.wait(ctx, s.Bot)
+	if err != nil {
+		return nil, err
+	}
+	return message.Message, nil
+}
@@ -0,0 +1,107 @@
+package onebot
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestWaitNextMessage(t *testing.T) {
+	for _, concurrentEvent := range []bool{false, true} {
+		b := newBot(0, concurrentEvent, defaultWriteTimeout)
+		result := make(chan string, 1)
+		b.ListenGroupMessage(func(message *GroupMessage) bool {
+			if message.RawMessage != "start" {
+				result <- "normal handler: " + message.RawMessage
+				return true
+			}
+			s := b.NewSession(message.GroupId, message.UserId)
+			s.Timeout = time.Second
+			reply, err := s.Next(context.Background())
+			if err != nil {
+				result <- err.Error()
+			} else {
+				result <- reply.CQString()
+			}
+			return true
+		})
+		b.onMessage(slog.Default(), []byte(`{"post_type":"message","message_type":"group","group_id":1000,"user_id":2000,"raw_message":"start","message":"start"}`))
+		time.Sleep(100 * time.Millisecond)
+		b.onMessage(slog.Default(), []byte(`{"post_type":"message","message_type":"group","group_id":1000,"user_id":3000,"raw_message":"other","message":"other"}`))
+		b.onMessage(slog.Default(), []byte(`{"post_type":"message","message_type":"group","group_id":1000,"user_id":2000,"raw_message":"answer","message":"answer"}`))
+		// 单线程处理事件时，其它人的消息要等到对话结束后才处理
+		results := make(map[string]bool)
+		for range 2 {
+			select {
+			case r := <-result:
+				results[r] = true
+			case <-time.After(2 * time.Second):
+				t.Fatal(concurrentEvent, "deadlock")
+			}
+		}
+		if !results["answer"] || !results["normal handler: other"] {
+			t.Fatal(concurrentEvent, results)
+		}
+	}
+
+	b := newBot(0, false, defaultWriteTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := b.WaitNextPrivateMessage(ctx, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal(err)
+	}
+	if len(b.waiters) != 0 {
+		t.Fatal(b.waiters)
+	}
+}
+
+func TestSessionMiddleware(t *testing.T) {
+	for _, concurrentEvent := range []bool{false, true} {
+		b := newBot(0, concurrentEvent, defaultWriteTimeout)
+		// 黑名单中间件拦截的消息不能被 Session 等到
+		b.Use(func(next EventHandler) EventHandler {
+			return func(e *Event) {
+				if m, ok := e.Value.(*GroupMessage); ok && m.RawMessage == "spam" {
+					return
+				}
+				next(e)
+			}
+		})
+		result := make(chan string, 2)
+		b.ListenGroupMessage(func(message *GroupMessage) bool {
+			if message.RawMessage != "start" {
+				result <- "normal handler: " + message.RawMessage
+				return true
+			}
+			s := b.NewSession(message.GroupId, message.UserId)
+			s.Timeout = time.Second
+			reply, err := s.Next(context.Background())
+			if err != nil {
+				result <- err.Error()
+			} else {
+				result <- reply.CQString()
+			}
+			return true
+		})
+		b.onMessage(slog.Default(), []byte(`{"post_type":"message","message_type":"group","group_id":1000,"user_id":2000,"raw_message":"start","message":"start"}`))
+		time.Sleep(100 * time.Millisecond)
+		b.onMessage(slog.Default(), []byte(`{"post_type":"message","message_type":"group","group_id":1000,"user_id":2000,"raw_message":"spam","message":"spam"}`))
+		time.Sleep(100 * time.Millisecond)
+		b.onMessage(slog.Default(), []byte(`{"post_type":"message","message_type":"group","group_id":1000,"user_id":2000,"raw_message":"answer","message":"answer"}`))
+		select {
+		case r := <-result:
+			if r != "answer" {
+				t.Fatal(concurrentEvent, r)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal(concurrentEvent, "deadlock")
+		}
+		select {
+		case r := <-result:
+			t.Fatal(concurrentEvent, r)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package onebot
+
+import (
+	"hash/fnv"
+	"log/slog"
+	"runtime"
+
+	"github.com/tidwall/gjson"
+)
+
+// OverflowPolicy 分片处理事件时，某个协程的队列满了之后的处理方式
+type OverflowPolicy int
+
+const (
+	// OverflowDropNewest 丢弃新的事件，这是默认的处理方式
+	OverflowDropNewest OverflowPolicy = iota
+
+	// OverflowDropOldest 丢弃队列中最早的事件
+	OverflowDropOldest
+)
+
+// defaultShardQueueSize 分片处理事件时，每个协程默认的队列长度
+const defaultShardQueueSize = 1024
+
+// ShardOptions 分片处理事件的配置。
+//
+// 事件按照群号（频道消息为群组和频道 ID ，其它为QQ号）分配到固定的协程中，
+// 同一个群或者同一个人的事件按顺序处理，不同的群之间并发处理。
+//
+// 队列满了之后会丢弃事件而不是等待，因为等待期间无法读取新的消息，也就收不到API的响应，
+// 监听者中调用的API会一直等到超时。处理事件较慢、不希望丢弃事件时，请增大 QueueSize
+type ShardOptions struct {
+	Workers   int            // 协程数量，小于等于0时为CPU数量
+	QueueSize int            // 每个协程的队列长度，小于等于0时为1024
+	Overflow  OverflowPolicy // 队列满了之后的处理方式，默认为 OverflowDropNewest
+}
+
+// shards 分片处理事件的协程
+type shards struct {
+	overflow OverflowPolicy
+	queues   []chan func()
+}
+
+// newShards 启动处理事件的协程，done 被关闭时退出
+func newShards(opts ShardOptions, done <-chan struct{}) *shards {
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.NumCPU()
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = defaultShardQueueSize
+	}
+	s := &shards{overflow: opts.Overflow, queues: make([]chan func(), opts.Workers)}
+	for i := range s.queues {
+		q := make(chan func(), opts.QueueSize)
+		s.queues[i] = q
+		go func() {
+			for {
+				select {
+				case f := <-q:
+					f()
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+	return s
+}
+
+// shardKey 事件所属的对话
+func shardKey(raw gjson.Result) string {
+	if groupId := raw.Get("group_id"); groupId.Exists() {
+		return "group:" + groupId.String()
+	}
+	if guildId := raw.Get("guild_id"); guildId.Exists() {
+		return "channel:" + guildId.String() + ":" + raw.Get("channel_id").String()
+	}
+	return "user:" + raw.Get("user_id").String()
+}
+
+// put 把事件放入对应的队列，不会阻塞
+func (s *shards) put(log *slog.Logger, raw gjson.Result, f func()) {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(shardKey(raw)))
+	q := s.queues[h.Sum32()%uint32(len(s.queues))]
+	switch s.overflow {
+	case OverflowDropOldest:
+		for {
+			select {
+			case q <- f:
+				return
+			default:
+			}
+			select {
+			case <-q:
+				log.Warn("event queue is full, drop the oldest event")
+			default:
+			}
+		}
+	default:
+		select {
+		case q <- f:
+		default:
+			log.Warn("event queue is full, drop the newest event")
+		}
+	}
+}
+
+// runEvent 处理事件，分片处理时放入对应的队列，否则同 Run
+func (b *Bot) runEvent(log *slog.Logger, e *incomingEvent, f func()) {
+	if b.shards != nil {
+		b.shards.put(log, e.Raw, f)
+	} else {
+		b.Run(f)
+	}
+}
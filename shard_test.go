@@ -0,0 +1,157 @@
+package onebot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/tidwall/gjson"
+)
+
+func TestShards(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+	s := newShards(ShardOptions{Workers: 4}, done)
+	var lock sync.Mutex
+	var wg, others sync.WaitGroup
+	results := make(map[int64][]int)
+	started, block := make(chan struct{}), make(chan struct{})
+	for i := range 100 {
+		for _, groupId := range []int64{1000, 2000, 3000} {
+			raw := gjson.Parse(`{"post_type":"message","message_type":"group","group_id":` + strconv.FormatInt(groupId, 10) + `}`)
+			wg.Add(1)
+			if groupId != 1000 {
+				others.Add(1)
+			}
+			s.put(slog.Default(), raw, func() {
+				defer wg.Done()
+				if groupId == 1000 && i == 0 {
+					close(started)
+					<-block // 一个群的事件阻塞时，不影响其它群
+				}
+				lock.Lock()
+				results[groupId] = append(results[groupId], i)
+				lock.Unlock()
+				if groupId != 1000 {
+					others.Done()
+				}
+			})
+		}
+	}
+	<-started
+	others.Wait()
+	lock.Lock()
+	if len(results[1000]) != 0 {
+		t.Fatal(results[1000])
+	}
+	lock.Unlock()
+	close(block)
+	wg.Wait()
+	for groupId, r := range results {
+		if len(r) != 100 {
+			t.Fatal(groupId, len(r))
+		}
+		for i := range r {
+			if r[i] != i {
+				t.Fatal(groupId, r)
+			}
+		}
+	}
+}
+
+func TestShardsOverflow(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+	for policy, expected := range map[OverflowPolicy]int{OverflowDropNewest: 0, OverflowDropOldest: 2} {
+		s := newShards(ShardOptions{Workers: 1, QueueSize: 1, Overflow: policy}, done)
+		started, block := make(chan struct{}), make(chan struct{})
+		s.put(slog.Default(), gjson.Result{}, func() {
+			close(started)
+			<-block
+		})
+		<-started
+		ran := make(chan int, 3)
+		for i := range 3 {
+			s.put(slog.Default(), gjson.Result{}, func() { ran <- i })
+		}
+		close(block)
+		if i := <-ran; i != expected {
+			t.Fatal(policy, i)
+		}
+		// 队列已经空了，再放入一个事件，它处理完时之前的事件一定都处理完了
+		finished := make(chan struct{})
+		s.put(slog.Default(), gjson.Result{}, func() { close(finished) })
+		<-finished
+		if len(ran) != 0 {
+			t.Fatal(policy, <-ran)
+		}
+	}
+}
+
+func TestShardsExit(t *testing.T) {
+	n := runtime.NumGoroutine()
+	for range 10 {
+		done := make(chan struct{})
+		newShards(ShardOptions{Workers: 4}, done)
+		close(done)
+	}
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > n {
+		if time.Now().After(deadline) {
+			t.Fatal("goroutine leak", runtime.NumGoroutine()-n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestShardedEventCallApi(t *testing.T) {
+	addr := newTestServer(t, func(c *websocket.Conn) {
+		for first := true; ; first = false {
+			_, message, err := c.ReadMessage()
+			if err != nil {
+				return
+			}
+			resp := fmt.Sprintf(`{"status":"ok","retcode":0,"data":{"user_id":1,"nickname":"bot"},"echo":%d}`,
+				gjson.GetBytes(message, "echo").Int())
+			if err = c.WriteMessage(websocket.TextMessage, []byte(resp)); err != nil {
+				return
+			}
+			if first {
+				// 同一个群的事件超过队列长度，处理第一个事件时调用的API的响应在这些事件之后
+				for i := range 10 {
+					event := fmt.Sprintf(`{"post_type":"message","message_type":"group","group_id":1000,"user_id":2000,"message_id":%d,"message":"hi"}`, i)
+					if err = c.WriteMessage(websocket.TextMessage, []byte(event)); err != nil {
+						return
+					}
+				}
+			}
+		}
+	})
+	b, err := ConnectWithOptions(context.Background(), addr,
+		WithShardedEvent(ShardOptions{Workers: 1, QueueSize: 1}),
+		WithReconnectInterval(time.Hour),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = b.Close() }()
+	results := make(chan error, 10)
+	b.ListenGroupMessage(func(message *GroupMessage) bool {
+		_, err := b.WithTimeout(2 * time.Second).GetLoginInfo()
+		results <- err
+		return true
+	})
+	if _, err = b.GetLoginInfo(); err != nil {
+		t.Fatal(err)
+	}
+	// 队列满了之后的事件被丢弃，不会阻塞读取，监听者中调用的API可以收到响应
+	if err = <-results; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,152 @@
+package onebot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// Transport 调用API的方式
+type Transport interface {
+	// Call 调用API，返回OneBot的完整响应，包含 status 、 retcode 、 data 等字段。
+	// retcode 的检查和 data 的解析由调用者负责
+	//
+	// 超时和取消由 ctx 控制，ctx 结束时应当立即返回 ctx.Err()
+	Call(ctx context.Context, action string, params any) (gjson.Result, error)
+}
+
+// wsTransport 通过 Bot 的WebSocket连接调用API，用 echo 字段匹配响应
+type wsTransport struct {
+	b *Bot
+}
+
+func (t *wsTransport) Call(ctx context.Context, action string, params any) (gjson.Result, error) {
+	b := t.b
+	msg := &requestMessage{
+		Echo:   b.echo.Add(1),
+		Action: action,
+		Params: params,
+	}
+	echo := msg.Echo
+	buf, err := json.Marshal(msg)
+	if err != nil {
+		slog.Error("json marshal failed", "error", err)
+		return gjson.Result{}, err
+	}
+	w := b.api.writer()
+	if w == nil && b.waitReconnect && !b.closed.Load() {
+		w, err = b.api.wait(ctx, b.done)
+		if err != nil {
+			return gjson.Result{}, err
+		}
+	}
+	if w == nil {
+		slog.Error("disconnected, send failed, please wait for reconnecting")
+		return gjson.Result{}, ErrDisconnected
+	}
+	// 断线时会关闭所有登记的 ch ，所以取得 writer 之后再登记
+	ch := make(chan gjson.Result, 1)
+	b.syncIdMap.Store(echo, ch)
+	b.record(FrameOut, buf)
+	if err = w.write(ctx, buf); err != nil {
+		b.syncIdMap.Delete(echo)
+		slog.Error("send error", "error", err)
+		return gjson.Result{}, err
+	}
+	slog.Debug("send", "msg", string(buf))
+	select {
+	case result, ok := <-ch:
+		if !ok {
+			return gjson.Result{}, ErrDisconnected
+		}
+		return result, nil
+	case <-ctx.Done():
+		b.syncIdMap.Delete(echo)
+		return gjson.Result{}, ctx.Err()
+	}
+}
+
+// HttpTransport 通过OneBot的HTTP API调用，每次调用都是一次独立的 POST 请求，不需要保持连接
+type HttpTransport struct {
+	Url         string       // HTTP API的地址，例如 http://127.0.0.1:5700
+	AccessToken string       // 如果不为空，则通过 Authorization 头发送
+	Client      *http.Client // 为nil时使用 http.DefaultClient
+
+	// Version OneBot标准的版本，默认为 ProtocolV11 。
+	// OneBot 11 把 action 放在路径中，OneBot 12 则把整个请求（包括 action 和 params ）放在请求体中
+	Version ProtocolVersion
+}
+
+func (t *HttpTransport) Call(ctx context.Context, action string, params any) (gjson.Result, error) {
+	if params == nil {
+		params = struct{}{}
+	}
+	url := strings.TrimSuffix(t.Url, "/") + "/" + action
+	var reqBody any = params
+	if t.Version == ProtocolV12 {
+		url = t.Url
+		reqBody = &struct {
+			Action string `json:"action"`
+			Params any    `json:"params"`
+		}{action, params}
+	}
+	buf, err := json.Marshal(reqBody)
+	if err != nil {
+		slog.Error("json marshal failed", "error", err)
+		return gjson.Result{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(buf))
+	if err != nil {
+		return gjson.Result{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(t.AccessToken) > 0 {
+		req.Header.Set("Authorization", "Bearer "+t.AccessToken)
+	}
+	client := t.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	slog.Debug("send", "action", action, "params", string(buf))
+	resp, err := client.Do(req)
+	if err != nil {
+		slog.Error("send error", "error", err)
+		return gjson.Result{}, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return gjson.Result{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		// 按照OneBot的约定，HTTP状态码 4xx 与 retcode 14xx 一一对应
+		err = &ActionError{Action: action, Retcode: int64(1000 + resp.StatusCode), Status: "failed", Message: resp.Status}
+		if resp.StatusCode < 400 || resp.StatusCode >= 500 {
+			err = fmt.Errorf("action %s failed, http status: %s", action, resp.Status)
+		}
+		slog.Error("request failed", "error", err)
+		return gjson.Result{}, err
+	}
+	slog.Debug("recv", "msg", string(body))
+	if !gjson.ValidBytes(body) {
+		return gjson.Result{}, errors.New("invalid json response")
+	}
+	return gjson.ParseBytes(body), nil
+}
+
+// NewHttpBot 新建一个只通过HTTP API调用的 Bot，不建立WebSocket连接，适合短时间运行的任务。
+//
+// concurrentEvent 参数的含义同 Connect
+func NewHttpBot(url, accessToken string, qq int64, concurrentEvent bool) *Bot {
+	b := newBot(qq, concurrentEvent, defaultWriteTimeout)
+	b.SetTransport(&HttpTransport{Url: url, AccessToken: accessToken})
+	return b
+}
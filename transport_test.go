@@ -0,0 +1,84 @@
+package onebot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHttpTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/send_group_msg" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var params struct {
+			GroupId int64 `json:"group_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil || params.GroupId != 1000 {
+			_, _ = w.Write([]byte(`{"status":"failed","retcode":1400,"data":null}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"ok","retcode":0,"data":{"message_id":123}}`))
+	}))
+	defer server.Close()
+
+	b := NewHttpBot(server.URL, "token", 1, true)
+	if b.State() != StateNoWebSocket {
+		t.Fatal(b.State())
+	}
+	id, err := b.SendGroupMessage(1000, MessageChain{&Text{Text: "123"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 123 {
+		t.Fatal(id)
+	}
+	var actionErr *ActionError
+	_, err = b.SendGroupMessage(1001, MessageChain{&Text{Text: "123"}})
+	if !errors.As(err, &actionErr) || actionErr.Retcode != RetcodeBadRequest || actionErr.Action != "send_group_msg" {
+		t.Fatal(err)
+	}
+	if err = b.DeleteMessage(123); !errors.Is(err, ErrUnsupportedAction) {
+		t.Fatal(err)
+	}
+
+	b = NewHttpBot(server.URL, "wrong", 1, true)
+	_, err = b.SendGroupMessage(1000, MessageChain{&Text{Text: "123"}})
+	if !errors.As(err, &actionErr) || actionErr.Retcode != RetcodeUnauthorized {
+		t.Fatal(err)
+	}
+	_ = b.Close()
+	if b.State() != StateClosed {
+		t.Fatal(b.State())
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(500 * time.Millisecond):
+		}
+		_, _ = w.Write([]byte(`{"status":"ok","retcode":0,"data":null}`))
+	}))
+	defer server.Close()
+
+	b := NewHttpBot(server.URL, "", 1, true)
+	if err := b.WithTimeout(50 * time.Millisecond).CleanCache(); !errors.Is(err, ErrTimeout) {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	if err := b.WithContext(ctx).CleanCache(); !errors.Is(err, context.Canceled) {
+		t.Fatal(err)
+	}
+}